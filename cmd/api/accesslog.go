@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arnab4477/Parkour_API/internal/accesslog"
+)
+
+// sampleRates type holds the share of requests that are access logged for a path prefix
+// It implements flag.Value so that the -access-log-sample flag can be given more than once
+type sampleRates map[string]float64
+
+// Method that returns the rates as they are written in the flag
+func (sr sampleRates) String() string {
+	prefixes := make([]string, 0, len(sr))
+	for prefix := range sr {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+
+	rates := make([]string, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		rates = append(rates, fmt.Sprintf("%s=%g", prefix, sr[prefix]))
+	}
+	return strings.Join(rates, ",")
+}
+
+// Method that parses a rate in the form prefix=rate, such as /v1/movements=0.1
+func (sr sampleRates) Set(value string) error {
+	prefix, rate, found := strings.Cut(value, "=")
+	if !found || !strings.HasPrefix(prefix, "/") {
+		return fmt.Errorf("sample rate must be in the form /path/prefix=rate")
+	}
+
+	r, err := strconv.ParseFloat(rate, 64)
+	if err != nil || r < 0 || r > 1 {
+		return fmt.Errorf("invalid sample rate %q for %q, must be between 0 and 1", rate, prefix)
+	}
+
+	sr[prefix] = r
+	return nil
+}
+
+// Method that returns the sample rate of the longest prefix matching path, 1 if none matches
+func (sr sampleRates) rate(path string) float64 {
+	rate, longest := 1.0, -1
+	for prefix, r := range sr {
+		if strings.HasPrefix(path, prefix) && len(prefix) > longest {
+			rate, longest = r, len(prefix)
+		}
+	}
+	return rate
+}
+
+// responseRecorder wraps a http.ResponseWriter to record the status code and the size of the body
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+// Method that records the status code before writing it
+func (rw *responseRecorder) WriteHeader(status int) {
+	if !rw.wroteHeader {
+		rw.status = status
+		rw.wroteHeader = true
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+// Method that records the size of the body as it is written
+func (rw *responseRecorder) Write(b []byte) (int, error) {
+	if !rw.wroteHeader {
+		rw.WriteHeader(http.StatusOK)
+	}
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytes += int64(n)
+	return n, err
+}
+
+// Method that returns the wrapped http.ResponseWriter, for http.ResponseController
+func (rw *responseRecorder) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// Middleware that writes an access log entry for every request once it has been served
+// Errors are always logged, the other responses are sampled with -access-log-sample
+func (app *application) logAccess(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rw := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(rw, r)
+
+		if rw.status < http.StatusBadRequest && rand.Float64() >= app.config.accessLog.samples.rate(r.URL.Path) {
+			return
+		}
+
+		entry := accesslog.Entry{
+			Time:      start,
+			ClientIP:  app.clientIP(r),
+			Method:    r.Method,
+			URI:       r.URL.RequestURI(),
+			Proto:     r.Proto,
+			Status:    rw.status,
+			Bytes:     rw.bytes,
+			Duration:  time.Since(start),
+			Referer:   r.Referer(),
+			UserAgent: r.UserAgent(),
+		}
+		if info, ok := app.contextGetRequestInfo(r); ok {
+			entry.RequestID = info.id
+			if info.user != nil && !info.user.IsAnonymous() {
+				entry.UserID = strconv.FormatInt(info.user.ID, 10)
+			}
+		}
+
+		err := app.accessLog.Log(entry)
+		if err != nil {
+			app.logger.PrintError(fmt.Errorf("access log: %w", err), nil)
+		}
+	})
+}
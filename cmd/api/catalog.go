@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/arnab4477/Parkour_API/internal/catalog"
+	"github.com/arnab4477/Parkour_API/internal/validator"
+	"github.com/julienschmidt/httprouter"
+)
+
+// The max size of an import file
+const maxImportBytes = 10 * 1_048_576
+
+// Handler method on the app instance for the GET /admin/movements/export endpount
+func (app *application) exportMovementsHandler(w http.ResponseWriter, r *http.Request, _ps httprouter.Params) {
+
+	// Read and validate the format of the export
+	format := app.readStrings(r.URL.Query(), "format", catalog.FormatJSON)
+
+	v := validator.NewValidator()
+	if v.Check(!validator.In(format, catalog.Formats...), "format", "must be json, ndjson or csv"); !v.NoErrors() {
+		app.failedValidationError(w, r, v.Errors)
+		return
+	}
+
+	// Get the whole catalog from the database
+	movements, err := app.models.Movements.ExportMovements(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// Encode the catalog before writing anything so that an error can still be sent as JSON
+	var buf bytes.Buffer
+	err = catalog.Export(&buf, format, movements)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// Send the file as an attachment
+	w.Header().Set("Content-Type", catalog.ContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="movements.%s"`, format))
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// Handler method on the app instance for the POST /admin/movements/import endpount
+func (app *application) importMovementsHandler(w http.ResponseWriter, r *http.Request, _ps httprouter.Params) {
+	queries := r.URL.Query()
+
+	// The format can be given as a query parameter, else it is taken from the Content-Type header
+	format := app.readStrings(queries, "format", catalog.FormatFromContentType(r.Header.Get("Content-Type")))
+	if format == "" {
+		format = catalog.FormatJSON
+	}
+	dryRun := app.readStrings(queries, "dry_run", "false")
+
+	v := validator.NewValidator()
+	v.Check(!validator.In(format, catalog.Formats...), "format", "must be json, ndjson or csv")
+	v.Check(!validator.In(dryRun, "true", "false"), "dry_run", "must be true or false")
+	if !v.NoErrors() {
+		app.failedValidationError(w, r, v.Errors)
+		return
+	}
+
+	// Read the rows from the request body
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+	rows, err := catalog.Import(r.Body, format)
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			err = fmt.Errorf("request body cannot be larger than %d bytes", maxImportBytes)
+		}
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if len(rows) == 0 {
+		app.badRequestResponse(w, r, errors.New("body must contain at least one movement"))
+		return
+	}
+
+	// Validate and write all the rows in a single transaction
+	report, err := app.models.Movements.ImportMovements(r.Context(), rows, dryRun == "true")
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// If any of the rows failed then nothing was written, send the report with the errors
+	status := http.StatusOK
+	if report.Failed > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+
+	err = app.writeJSON(w, r, envelope{"import": report}, status, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
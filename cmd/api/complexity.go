@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// How many movements a query of the movements list is expected to return when it doesn't set pageSize
+const graphqlDefaultPageSize = 20
+
+// How many movements the prerequisites and the variations of a movement are expected to hold,
+// for the cost of a query
+const graphqlListSize = 10
+
+// complexity holds how deep the fields of a selection go and how much they are expected to cost
+type complexity struct {
+	depth int
+	cost  int
+}
+
+// Method on the app instance that refuses the queries nested deeper than -graphql-max-depth
+// or costing more than -graphql-max-cost
+// Every field costs 1, and the fields under a list cost as much as the list is expected to hold
+// The introspection fields are left out, the tools that read the schema go quite deep
+func (app *application) checkGraphQLLimits(document *ast.Document, operationName string, variables map[string]interface{}) error {
+	fragments := make(map[string]*ast.FragmentDefinition)
+	var operations []*ast.OperationDefinition
+	for _, definition := range document.Definitions {
+		switch definition := definition.(type) {
+		case *ast.FragmentDefinition:
+			fragments[definition.Name.Value] = definition
+		case *ast.OperationDefinition:
+			if operationName == "" || (definition.Name != nil && definition.Name.Value == operationName) {
+				operations = append(operations, definition)
+			}
+		}
+	}
+
+	// Executing the query fails anyway if the operation can't be told apart
+	if len(operations) != 1 {
+		return nil
+	}
+
+	m := measurer{fragments: fragments, variables: variables, measured: make(map[string]complexity)}
+	c := m.measure(operations[0].SelectionSet)
+
+	if maxDepth := app.config.graphql.maxDepth; maxDepth > 0 && c.depth > maxDepth {
+		return fmt.Errorf("the query is nested %d levels deep, it must not be more than %d", c.depth, maxDepth)
+	}
+	if maxCost := app.config.graphql.maxCost; maxCost > 0 && c.cost > maxCost {
+		return fmt.Errorf("the query costs %d, it must not cost more than %d", c.cost, maxCost)
+	}
+	return nil
+}
+
+// measurer walks the selections of a query, the fragments are measured only once
+// The validation of the query has already ruled out the fragments that spread themselves
+type measurer struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	measured  map[string]complexity
+}
+
+// Method that returns the complexity of a selection set
+func (m measurer) measure(selectionSet *ast.SelectionSet) complexity {
+	var c complexity
+	if selectionSet == nil {
+		return c
+	}
+
+	add := func(depth, cost int) {
+		if depth > c.depth {
+			c.depth = depth
+		}
+		c.cost += cost
+	}
+
+	for _, selection := range selectionSet.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(selection.Name.Value, "__") {
+				continue
+			}
+			children := m.measure(selection.SelectionSet)
+			add(children.depth+1, 1+m.listSize(selection)*children.cost)
+
+		case *ast.InlineFragment:
+			fragment := m.measure(selection.SelectionSet)
+			add(fragment.depth, fragment.cost)
+
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			fragment, ok := m.measured[name]
+			if !ok {
+				if definition, found := m.fragments[name]; found {
+					fragment = m.measure(definition.SelectionSet)
+				}
+				m.measured[name] = fragment
+			}
+			add(fragment.depth, fragment.cost)
+		}
+	}
+	return c
+}
+
+// Method that returns how many items a field is expected to return
+func (m measurer) listSize(field *ast.Field) int {
+	switch field.Name.Value {
+	case "movements":
+		for _, argument := range field.Arguments {
+			if argument.Name.Value == "pageSize" {
+				if size, ok := m.intValue(argument.Value); ok && size > 0 {
+					return size
+				}
+			}
+		}
+		return graphqlDefaultPageSize
+	case "prerequisites", "variations":
+		return graphqlListSize
+	default:
+		return 1
+	}
+}
+
+// Method that returns the value of an Int argument, written in the query or passed as a variable
+func (m measurer) intValue(value ast.Value) (int, bool) {
+	switch value := value.(type) {
+	case *ast.IntValue:
+		n, err := strconv.Atoi(value.Value)
+		return n, err == nil
+	case *ast.Variable:
+		// The variables are decoded from JSON, so the numbers are float64
+		n, ok := m.variables[value.Name.Value].(float64)
+		return int(n), ok
+	}
+	return 0, false
+}
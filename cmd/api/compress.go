@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+// The encodings the API can compress the responses with, from the most to the least preferred
+const (
+	encodingBrotli = "br"
+	encodingGzip   = "gzip"
+)
+
+// Pools of the encoders, so that their large internal buffers are reused between responses
+var (
+	gzipPool = sync.Pool{New: func() interface{} {
+		w, _ := gzip.NewWriterLevel(io.Discard, gzip.DefaultCompression)
+		return w
+	}}
+	brotliPool = sync.Pool{New: func() interface{} {
+		// The default level of 6 is too slow to compress every response on the fly
+		return brotli.NewWriterLevel(io.Discard, 4)
+	}}
+)
+
+// encoder is what the gzip and brotli writers have in common
+type encoder interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+	Flush() error
+}
+
+// Function that returns the best encoding the client accepts, or "" if it accepts none of them
+func negotiateEncoding(acceptEncoding string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		q := 1.0
+		if params = strings.TrimSpace(params); strings.HasPrefix(params, "q=") {
+			parsed, err := strconv.ParseFloat(strings.TrimPrefix(params, "q="), 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+
+		// Brotli wins a tie as it makes smaller responses
+		if (name == encodingBrotli || name == encodingGzip) && q > 0 &&
+			(q > bestQ || (q == bestQ && name == encodingBrotli)) {
+			best, bestQ = name, q
+		}
+	}
+	return best
+}
+
+// compressWriter wraps a http.ResponseWriter to compress the body once it is large enough
+// The first minSize bytes are buffered, if the response ends before that it is sent as it is
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	minSize  int
+
+	buf         bytes.Buffer
+	status      int
+	wroteHeader bool
+	decided     bool // If the body is being compressed or sent as it is
+	enc         encoder
+}
+
+// Method that holds the status back until it is known if the body is compressed
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.status = status
+	cw.wroteHeader = true
+
+	// These responses have no body to compress
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		cw.passThrough()
+	}
+}
+
+// Method that buffers the start of the body and compresses the rest once it is large enough
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+
+	if !cw.decided {
+		// A body that is encoded already, or whose size is known to be small, is sent as it is
+		if cw.Header().Get("Content-Encoding") != "" {
+			cw.passThrough()
+		} else if length, err := strconv.Atoi(cw.Header().Get("Content-Length")); err == nil && length < cw.minSize {
+			cw.passThrough()
+		} else {
+			cw.buf.Write(b)
+			if cw.buf.Len() < cw.minSize {
+				return len(b), nil
+			}
+			return len(b), cw.startCompressing()
+		}
+	}
+
+	if cw.enc != nil {
+		return cw.enc.Write(b)
+	}
+	return cw.ResponseWriter.Write(b)
+}
+
+// Method that sends the headers and the buffered body uncompressed
+func (cw *compressWriter) passThrough() {
+	cw.decided = true
+	cw.ResponseWriter.WriteHeader(cw.status)
+	if cw.buf.Len() > 0 {
+		cw.ResponseWriter.Write(cw.buf.Bytes())
+		cw.buf.Reset()
+	}
+}
+
+// Method that sends the headers for a compressed body and compresses the buffered start of it
+func (cw *compressWriter) startCompressing() error {
+	cw.decided = true
+
+	h := cw.Header()
+	h.Set("Content-Encoding", cw.encoding)
+	h.Del("Content-Length") // The length of the compressed body is not known up front
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	if cw.encoding == encodingBrotli {
+		cw.enc = brotliPool.Get().(*brotli.Writer)
+	} else {
+		cw.enc = gzipPool.Get().(*gzip.Writer)
+	}
+	cw.enc.Reset(cw.ResponseWriter)
+
+	_, err := cw.enc.Write(cw.buf.Bytes())
+	cw.buf.Reset()
+	return err
+}
+
+// Method that ends the response, sending what is still buffered and returning the encoder to its pool
+func (cw *compressWriter) Close() error {
+	if !cw.decided {
+		// Nothing was written at all, not even the status
+		if !cw.wroteHeader {
+			return nil
+		}
+		cw.passThrough()
+		return nil
+	}
+
+	if cw.enc == nil {
+		return nil
+	}
+
+	err := cw.enc.Close()
+	cw.enc.Reset(io.Discard)
+	switch enc := cw.enc.(type) {
+	case *brotli.Writer:
+		brotliPool.Put(enc)
+	case *gzip.Writer:
+		gzipPool.Put(enc)
+	}
+	cw.enc = nil
+	return err
+}
+
+// Method that sends what has been written so far, compressing it if it is large enough
+func (cw *compressWriter) Flush() {
+	if !cw.decided && cw.wroteHeader {
+		if cw.buf.Len() > 0 {
+			cw.startCompressing()
+		} else {
+			cw.passThrough()
+		}
+	}
+	if cw.enc != nil {
+		cw.enc.Flush()
+	}
+	if flusher, ok := cw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Method that returns the wrapped http.ResponseWriter, for http.ResponseController
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// Middleware that compresses the response bodies with brotli or gzip, if the client accepts either
+// Bodies smaller than -compression-min-size are sent as they are, as compressing them saves little
+func (app *application) compress(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The response depends on the Accept-Encoding header even if this one is not compressed
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding, minSize: app.config.compression.minSize}
+		defer cw.Close()
+
+		next.ServeHTTP(cw, r)
+	})
+}
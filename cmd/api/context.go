@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/arnab4477/Parkour_API/internal/data"
 )
@@ -13,8 +14,31 @@ type contextKey string
 // Constamt to hold the string "user" as contextKey
 const userContextKey = contextKey("user")
 
+// Constant to hold the string "request" as contextKey
+const requestContextKey = contextKey("request")
+
+// Constant to hold the string "format" as contextKey
+const formatContextKey = contextKey("format")
+
+// Constant to hold the string "graphql" as contextKey
+const graphqlContextKey = contextKey("graphql")
+
+// requestInfo struct holds the ID of a request, when it started and who made it, for the logs
+// It is kept as a pointer in the context so that the middleware around the router can see
+// the user that authenticate sets further down the chain
+type requestInfo struct {
+	id    string
+	start time.Time
+	user  *data.User
+	route string // The pattern of the matched route, for the metrics
+}
+
 // Method to set the user context to the request context
 func (app *application) contextSetUser(r *http.Request, user *data.User) *http.Request {
+	if info, ok := app.contextGetRequestInfo(r); ok {
+		info.user = user
+	}
+
 	ctx := context.WithValue(r.Context(), userContextKey, user)
 	return r.WithContext(ctx)
 }
@@ -27,3 +51,36 @@ func (app *application) contextGetUser(r *http.Request) *data.User {
 	}
 	return user
 }
+
+// Method to retrieve the User struct from the request context, if authenticate has set one
+func (app *application) contextLookupUser(r *http.Request) (*data.User, bool) {
+	user, ok := r.Context().Value(userContextKey).(*data.User)
+	return user, ok
+}
+
+// Method to set the request information to the request context
+func (app *application) contextSetRequestInfo(r *http.Request, info *requestInfo) *http.Request {
+	ctx := context.WithValue(r.Context(), requestContextKey, info)
+	return r.WithContext(ctx)
+}
+
+// Method to retrieve the request information from the request context, if the requestID middleware has set it
+func (app *application) contextGetRequestInfo(r *http.Request) (*requestInfo, bool) {
+	info, ok := r.Context().Value(requestContextKey).(*requestInfo)
+	return info, ok
+}
+
+// Method to set the format of the response to the request context
+func (app *application) contextSetFormat(r *http.Request, format string) *http.Request {
+	ctx := context.WithValue(r.Context(), formatContextKey, format)
+	return r.WithContext(ctx)
+}
+
+// Method to retrieve the format of the response from the request context, JSON if negotiate has not set one
+func (app *application) contextGetFormat(r *http.Request) string {
+	format, ok := r.Context().Value(formatContextKey).(string)
+	if !ok {
+		return formatJSON
+	}
+	return format
+}
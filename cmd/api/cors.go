@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// trustedOrigins type holds the origins given with the -cors-trusted-origins flag
+// An origin is either exact, such as https://parkour.example.com, or matches every subdomain,
+// such as https://*.example.com, and "*" trusts every origin but never with credentials
+type trustedOrigins []string
+
+// Method that returns the origins as they are written in the flag
+func (to *trustedOrigins) String() string {
+	return strings.Join(*to, " ")
+}
+
+// Method that parses a space separated list of origins
+func (to *trustedOrigins) Set(value string) error {
+	origins := strings.Fields(value)
+	for _, origin := range origins {
+		if origin == "*" {
+			continue
+		}
+
+		scheme, host, found := strings.Cut(origin, "://")
+		if !found || scheme == "" || host == "" || strings.ContainsAny(host, "/?#@") {
+			return fmt.Errorf("invalid origin %q, must be in the form scheme://host[:port]", origin)
+		}
+		if strings.Contains(strings.TrimPrefix(host, "*."), "*") {
+			return fmt.Errorf("invalid origin %q, a wildcard can only be the first label of the host", origin)
+		}
+	}
+
+	*to = origins
+	return nil
+}
+
+// Method that reports if every origin is trusted
+func (to trustedOrigins) any() bool {
+	for _, origin := range to {
+		if origin == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// Method that reports if origin matches one of the trusted origins exactly or by wildcard
+func (to trustedOrigins) match(origin string) bool {
+	for _, trusted := range to {
+		if strings.EqualFold(origin, trusted) {
+			return true
+		}
+
+		// https://*.example.com matches https://app.example.com and https://a.b.example.com,
+		// but neither https://example.com nor http://app.example.com
+		prefix, suffix, found := strings.Cut(trusted, "://*.")
+		if !found {
+			continue
+		}
+		prefix += "://"
+		suffix = "." + suffix
+
+		if len(origin) <= len(prefix)+len(suffix) ||
+			!strings.EqualFold(origin[:len(prefix)], prefix) ||
+			!strings.EqualFold(origin[len(origin)-len(suffix):], suffix) {
+			continue
+		}
+
+		// The subdomain must be made of host characters, so that no port or path can be smuggled in
+		subdomain := origin[len(prefix) : len(origin)-len(suffix)]
+		if validSubdomain(subdomain) {
+			return true
+		}
+	}
+	return false
+}
+
+// Function that reports if s is made only of letters, digits, hyphens and dots
+func validSubdomain(s string) bool {
+	for _, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '.':
+		default:
+			return false
+		}
+	}
+	return !strings.HasPrefix(s, ".") && !strings.HasSuffix(s, ".")
+}
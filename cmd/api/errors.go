@@ -1,32 +1,95 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/arnab4477/Parkour_API/internal/data"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// Function that logs error messgaes
-func (app *application) logError(r *http.Request, err error) {
-	app.logger.Println(err)
+// Non standard status code, used by nginx among others, for a request the client gave up on
+const statusClientClosedRequest = 499
+
+// The message sent to the client in the case of an Internal Server Error
+const serverErrorMessage = "the server encountered a problem and could not proceed with your request"
+
+// Function that logs error messgaes along with the details of the request
+func (app *application) logError(r *http.Request, status int, err error) {
+	app.logger.PrintError(err, app.requestProperties(r, status))
+}
+
+// Method that returns the details of a request for its log lines
+// The status is left out if it is 0, as it may not be known yet
+func (app *application) requestProperties(r *http.Request, status int) map[string]string {
+	properties := map[string]string{
+		"method": r.Method,
+		"uri":    r.URL.RequestURI(),
+	}
+
+	if info, ok := app.contextGetRequestInfo(r); ok {
+		properties["request_id"] = info.id
+		properties["duration"] = time.Since(info.start).String()
+	}
+	if user, ok := app.contextLookupUser(r); ok && !user.IsAnonymous() {
+		properties["user_id"] = strconv.FormatInt(user.ID, 10)
+	}
+	if status != 0 {
+		properties["status"] = strconv.Itoa(status)
+	}
+
+	// Link the log line to its trace, if it is being recorded
+	if spanContext := trace.SpanContextFromContext(r.Context()); spanContext.IsValid() {
+		properties["trace_id"] = spanContext.TraceID().String()
+	}
+
+	return properties
 }
 
 // Error handler that sends error response back as JSON with custom message and http status
+// The request ID is sent along so that users can quote it when they report a problem
 func (app *application) writeError(w http.ResponseWriter, r *http.Request, status int, message interface{}) {
-	err := app.writeJSON(w, envelope{"error": message}, status, nil)
+	env := envelope{"error": message}
+	if info, ok := app.contextGetRequestInfo(r); ok {
+		env["request_id"] = info.id
+	}
+
+	err := app.writeJSON(w, r, env, status, nil)
 	if err != nil {
-		app.logError(nil, err)
+		app.logError(r, http.StatusInternalServerError, err)
 		w.WriteHeader(500)
-		app.logger.Println(err)
 	}
 }
 
 // Handler that sends an error response in the case of an Internal Server Error
 func (app *application) serverErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	// Errors caused by a cancelled or timed out query are not server errors
+	if data.IsCanceled(err) {
+		app.canceledResponse(w, r, err)
+		return
+	}
 
-	// Write and send the appropriate error message
-	message := "the server encountered a problem and could not proceed with your request"
-	app.writeError(w, r, http.StatusInternalServerError, message)
-	app.logger.Println(err)
+	// Log the error and send the appropriate error message
+	app.logError(r, http.StatusInternalServerError, err)
+	app.writeError(w, r, http.StatusInternalServerError, serverErrorMessage)
+}
+
+// Handler that sends an error response in the case of a database call being cancelled
+// If the client went away nobody will read the response, otherwise the query timed out
+func (app *application) canceledResponse(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(r.Context().Err(), context.Canceled) {
+		app.logger.PrintInfo("request cancelled by the client", app.requestProperties(r, statusClientClosedRequest))
+		w.WriteHeader(statusClientClosedRequest)
+		return
+	}
+
+	app.logError(r, http.StatusGatewayTimeout, err)
+	message := "the server took too long to process your request, please try again"
+	app.writeError(w, r, http.StatusGatewayTimeout, message)
 }
 
 // Handler that sends an error response in the case of the method not being allowed
@@ -53,10 +116,27 @@ func (app *application) editConflictResponse(w http.ResponseWriter, r *http.Requ
 	app.writeError(w, r, http.StatusConflict, message)
 }
 
+// Handler that sends an error response when an Idempotency-Key is reused for a different request
+func (app *application) idempotencyKeyReusedResponse(w http.ResponseWriter, r *http.Request) {
+
+	// Write and send the appropriate error message
+	message := "the Idempotency-Key has already been used for a different request"
+	app.writeError(w, r, http.StatusConflict, message)
+}
+
+// Handler that sends an error response when the request with the same Idempotency-Key is still running
+func (app *application) idempotencyKeyInUseResponse(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Retry-After", "1")
+
+	// Write and send the appropriate error message
+	message := "a request with the same Idempotency-Key is still being processed, please try again later"
+	app.writeError(w, r, http.StatusConflict, message)
+}
+
 // Handler that sends an error response in the case of a Bad Request
 func (app *application) badRequestResponse(w http.ResponseWriter, r *http.Request, err error) {
+	app.logger.PrintInfo(err.Error(), app.requestProperties(r, http.StatusBadRequest))
 	app.writeError(w, r, http.StatusBadRequest, err.Error())
-	app.logger.Println(err)
 }
 
 // Handler that sends an error response in the case of an failed validation error
@@ -90,3 +170,28 @@ func (app *application) inactiveAccountResponse(w http.ResponseWriter, r *http.R
 	message := "user must be activated for this action"
 	app.writeError(w, r, http.StatusForbidden, message)
 }
+
+// Handler that sends an error response when the client accepts none of the formats of the response
+func (app *application) notAcceptableResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the requested format is not supported, it must be json, csv, xml or msgpack"
+	app.writeError(w, r, http.StatusNotAcceptable, message)
+}
+
+// Handler that sends an error response in the case of an user not having the required permission
+func (app *application) notPermittedResponse(w http.ResponseWriter, r *http.Request) {
+	message := "your user account doesn't have the necessary permissions to access this resource"
+	app.writeError(w, r, http.StatusForbidden, message)
+}
+
+// Handler that sends an error response in the case of a client making too many requests
+func (app *application) rateLimitExceededResponse(w http.ResponseWriter, r *http.Request, retryAfter time.Duration) {
+	// Never tell the client to retry straight away, it would only be limited again
+	seconds := ceilSeconds(retryAfter)
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+
+	message := "rate limit exceeded, please slow down"
+	app.writeError(w, r, http.StatusTooManyRequests, message)
+}
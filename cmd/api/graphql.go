@@ -0,0 +1,432 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/arnab4477/Parkour_API/internal/data"
+	"github.com/arnab4477/Parkour_API/internal/validator"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/julienschmidt/httprouter"
+)
+
+// graphqlRequest holds what the resolvers of a GraphQL request need, it is kept in the context
+type graphqlRequest struct {
+	r       *http.Request
+	user    *data.User
+	loaders *graphqlLoaders
+}
+
+// Function that returns the GraphQL request of the context of a resolver
+func graphqlRequestFrom(ctx context.Context) *graphqlRequest {
+	gr, ok := ctx.Value(graphqlContextKey).(*graphqlRequest)
+	if !ok {
+		panic("missing graphql request value in resolver context")
+	}
+	return gr
+}
+
+// graphqlValidationError is sent for the arguments that fail validation, with the errors by argument
+type graphqlValidationError struct {
+	errors map[string]string
+}
+
+func (e graphqlValidationError) Error() string {
+	return "failed validation"
+}
+
+func (e graphqlValidationError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"validation": e.errors}
+}
+
+// Method on the app instance that logs an error of a resolver and returns the one sent to the client
+// The details of the server errors are never sent
+func (app *application) graphqlServerError(ctx context.Context, err error) error {
+	gr := graphqlRequestFrom(ctx)
+	if data.IsCanceled(err) {
+		return errors.New("the request was cancelled or took too long")
+	}
+	app.logError(gr.r, http.StatusInternalServerError, err)
+	return errors.New(serverErrorMessage)
+}
+
+// Function that reads an ID argument as a movement or user id
+func graphqlIDArg(args map[string]interface{}) (int64, error) {
+	raw, _ := args["id"].(string)
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id < 1 {
+		return 0, graphqlValidationError{map[string]string{"id": "must be a positive integer"}}
+	}
+	return id, nil
+}
+
+// Function that reads a list argument of strings, an empty list if it is not set
+func graphqlStringsArg(args map[string]interface{}, name string) []string {
+	values, _ := args[name].([]interface{})
+	strs := make([]string, 0, len(values))
+	for _, value := range values {
+		if s, ok := value.(string); ok {
+			strs = append(strs, s)
+		}
+	}
+	return strs
+}
+
+// Method on the app instance that builds the GraphQL schema
+// It is read-only, the writes go through the REST endpoints
+func (app *application) newGraphQLSchema() (graphql.Schema, error) {
+	nonNullStrings := graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String)))
+
+	userType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "User",
+		Description: "A user, without anything private such as the email",
+		Fields: graphql.Fields{
+			"id":       &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"username": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+
+	accountType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Account",
+		Description: "The authenticated user",
+		Fields: graphql.Fields{
+			"id":        &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"username":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"email":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"activated": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"permissions": &graphql.Field{
+				Type: nonNullStrings,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					user := p.Source.(*data.User)
+					permissions, err := app.models.Permissions.GetAllForUser(p.Context, user.ID)
+					if err != nil {
+						return nil, app.graphqlServerError(p.Context, err)
+					}
+					return []string(permissions), nil
+				},
+			},
+		},
+	})
+
+	// The fields of a movement refer to the movement type itself, so they are a thunk
+	var movementType *graphql.Object
+	movementType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Movement",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+				"name":        &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"description": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"image":       &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"tutorials":   &graphql.Field{Type: nonNullStrings},
+				"skilltype":   &graphql.Field{Type: nonNullStrings},
+				"muscles":     &graphql.Field{Type: nonNullStrings},
+				"difficulty":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"equipments":  &graphql.Field{Type: nonNullStrings},
+				"prerequisite": &graphql.Field{
+					Type:        graphql.NewList(graphql.NewNonNull(graphql.String)),
+					Description: "The names of the movements to learn first",
+				},
+				"version": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+
+				"prerequisites": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(movementType))),
+					Description: "The movements named in prerequisite, the names without a movement are left out",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						movement := p.Source.(*data.Movement)
+						loader := graphqlRequestFrom(p.Context).loaders.movementNames
+
+						thunks := make([]func() (*data.Movement, error), len(movement.Prerequisites))
+						for i, name := range movement.Prerequisites {
+							thunks[i] = loader.load(p.Context, strings.ToLower(name))
+						}
+
+						return func() (interface{}, error) {
+							prerequisites := []*data.Movement{}
+							for _, thunk := range thunks {
+								prerequisite, err := thunk()
+								if err != nil {
+									return nil, app.graphqlServerError(p.Context, err)
+								}
+								if prerequisite != nil {
+									prerequisites = append(prerequisites, prerequisite)
+								}
+							}
+							return prerequisites, nil
+						}, nil
+					},
+				},
+
+				"variations": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(movementType))),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						movement := p.Source.(*data.Movement)
+						thunk := graphqlRequestFrom(p.Context).loaders.variations.load(p.Context, movement.ID)
+
+						return func() (interface{}, error) {
+							variations, err := thunk()
+							if err != nil {
+								return nil, app.graphqlServerError(p.Context, err)
+							}
+							if variations == nil {
+								variations = []*data.Movement{}
+							}
+							return variations, nil
+						}, nil
+					},
+				},
+
+				"variationOf": &graphql.Field{
+					Type:        movementType,
+					Description: "The movement this one is a variation of",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						movement := p.Source.(*data.Movement)
+						if movement.VariationOf == nil {
+							return nil, nil
+						}
+						thunk := graphqlRequestFrom(p.Context).loaders.movements.load(p.Context, *movement.VariationOf)
+
+						return func() (interface{}, error) {
+							parent, err := thunk()
+							if err != nil {
+								return nil, app.graphqlServerError(p.Context, err)
+							}
+							if parent == nil {
+								return nil, nil
+							}
+							return parent, nil
+						}, nil
+					},
+				},
+
+				"creator": &graphql.Field{
+					Type:        userType,
+					Description: "The user who added the movement, null for the imported ones",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						movement := p.Source.(*data.Movement)
+						if movement.CreatorID == nil {
+							return nil, nil
+						}
+						thunk := graphqlRequestFrom(p.Context).loaders.users.load(p.Context, *movement.CreatorID)
+
+						return func() (interface{}, error) {
+							creator, err := thunk()
+							if err != nil {
+								return nil, app.graphqlServerError(p.Context, err)
+							}
+							if creator == nil {
+								return nil, nil
+							}
+							return creator, nil
+						}, nil
+					},
+				},
+			}
+		}),
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"movement": &graphql.Field{
+				Type: movementType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := graphqlIDArg(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					thunk := graphqlRequestFrom(p.Context).loaders.movements.load(p.Context, id)
+
+					return func() (interface{}, error) {
+						movement, err := thunk()
+						if err != nil {
+							return nil, app.graphqlServerError(p.Context, err)
+						}
+						if movement == nil {
+							return nil, nil
+						}
+						return movement, nil
+					}, nil
+				},
+			},
+
+			"movements": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(movementType))),
+				Description: "The same filters as GET /v1/movements",
+				Args: graphql.FieldConfigArgument{
+					"name":       &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: ""},
+					"difficulty": &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: ""},
+					"skilltype":  &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+					"muscles":    &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+					"equipments": &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+					"sort":       &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: "id"},
+					"page":       &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 1},
+					"pageSize":   &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: graphqlDefaultPageSize},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					filters := data.Filters{
+						Sort:           p.Args["sort"].(string),
+						Page:           p.Args["page"].(int),
+						PageSize:       p.Args["pageSize"].(int),
+						SortSafeList:   []string{"id", "name", "difficulty", "-name", "-difficulty"},
+						FieldsSafeList: data.MovementFields,
+					}
+
+					v := validator.NewValidator()
+					if data.ValidateFilters(v, filters); !v.NoErrors() {
+						// The errors are named after the arguments, which are camel case here
+						if message, ok := v.Errors["page_size"]; ok {
+							delete(v.Errors, "page_size")
+							v.Errors["pageSize"] = message
+						}
+						return nil, graphqlValidationError{v.Errors}
+					}
+
+					movements, err := app.models.Movements.GetAllMovements(
+						p.Context, p.Args["name"].(string), p.Args["difficulty"].(string),
+						graphqlStringsArg(p.Args, "skilltype"), graphqlStringsArg(p.Args, "muscles"),
+						graphqlStringsArg(p.Args, "equipments"), filters,
+					)
+					if err != nil {
+						return nil, app.graphqlServerError(p.Context, err)
+					}
+					return movements, nil
+				},
+			},
+
+			"user": &graphql.Field{
+				Type: userType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := graphqlIDArg(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					thunk := graphqlRequestFrom(p.Context).loaders.users.load(p.Context, id)
+
+					return func() (interface{}, error) {
+						user, err := thunk()
+						if err != nil {
+							return nil, app.graphqlServerError(p.Context, err)
+						}
+						if user == nil {
+							return nil, nil
+						}
+						return user, nil
+					}, nil
+				},
+			},
+
+			"me": &graphql.Field{
+				Type:        accountType,
+				Description: "The user of the bearer token, an error without one",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					user := graphqlRequestFrom(p.Context).user
+					if user.IsAnonymous() {
+						return nil, errors.New("you are not authenticated for this action")
+					}
+					return user, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+// Method on the app instance that returns the handler for the POST /graphql endpoint
+// The schema is built once, when the routes are registered
+func (app *application) graphqlHandler() httprouter.Handle {
+	schema, err := app.newGraphQLSchema()
+	if err != nil {
+		// The schema never changes, an error here is a bug in it
+		panic(err)
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, _ps httprouter.Params) {
+		// Create a struct to hold the input
+		var input struct {
+			Query         string                 `json:"query"`
+			OperationName string                 `json:"operationName"`
+			Variables     map[string]interface{} `json:"variables"`
+			Extensions    map[string]interface{} `json:"extensions"`
+		}
+
+		// Decode the JSON request and send an appropriate response in case of an error
+		err := app.readJSON(w, r, &input)
+		if err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+
+		v := validator.NewValidator()
+		if v.Check(strings.TrimSpace(input.Query) == "", "query", "must be provided"); !v.NoErrors() {
+			app.failedValidationError(w, r, v.Errors)
+			return
+		}
+
+		// A query that can't be parsed, is invalid for the schema or goes over the limits
+		// is refused with a 400 before anything runs
+		document, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(input.Query), Name: "GraphQL request"})})
+		if err != nil {
+			app.writeGraphQLErrors(w, r, gqlerrors.FormatErrors(err))
+			return
+		}
+
+		validation := graphql.ValidateDocument(&schema, document, nil)
+		if !validation.IsValid {
+			app.writeGraphQLErrors(w, r, validation.Errors)
+			return
+		}
+
+		err = app.checkGraphQLLimits(document, input.OperationName, input.Variables)
+		if err != nil {
+			app.writeGraphQLErrors(w, r, gqlerrors.FormatErrors(err))
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), graphqlContextKey, &graphqlRequest{
+			r:       r,
+			user:    app.contextGetUser(r),
+			loaders: app.newGraphQLLoaders(),
+		})
+
+		result := graphql.Execute(graphql.ExecuteParams{
+			Schema:        schema,
+			AST:           document,
+			OperationName: input.OperationName,
+			Args:          input.Variables,
+			Context:       ctx,
+		})
+
+		// The errors of the resolvers are sent along with the data that could be resolved
+		env := envelope{"data": result.Data}
+		if len(result.Errors) > 0 {
+			env["errors"] = result.Errors
+		}
+
+		err = app.writeJSON(w, r, env, http.StatusOK, nil)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+		}
+	}
+}
+
+// Method on the app instance to send the errors of a GraphQL query that could not run
+func (app *application) writeGraphQLErrors(w http.ResponseWriter, r *http.Request, errs []gqlerrors.FormattedError) {
+	err := app.writeJSON(w, r, envelope{"errors": errs}, http.StatusBadRequest, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,546 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arnab4477/Parkour_API/internal/data"
+	"github.com/arnab4477/Parkour_API/internal/validator"
+	parkourv1 "github.com/arnab4477/Parkour_API/proto/parkour/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// The methods that need the bearer token of an activated user, like the routes behind requireActivatedUser
+var grpcActivatedMethods = map[string]bool{
+	parkourv1.MovementService_CreateMovement_FullMethodName: true,
+	parkourv1.MovementService_UpdateMovement_FullMethodName: true,
+	parkourv1.MovementService_DeleteMovement_FullMethodName: true,
+}
+
+// The limits of the methods that have the same limit as their route, the others use the global limit
+// The buckets are the same as the ones of the routes, so that a client can't double its limit over gRPC
+var grpcMethodLimits = map[string]string{
+	parkourv1.UserService_RegisterUser_FullMethodName: "register",
+	parkourv1.UserService_ActivateUser_FullMethodName: "activate",
+	parkourv1.UserService_Login_FullMethodName:        "login",
+}
+
+// Method on the app instance that returns the gRPC server with the services of proto/parkour/v1
+// The interceptors run in the same order as the middleware of the routes
+// Reflection is on so that tools such as grpcurl can list the services
+func (app *application) grpcServer() *grpc.Server {
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(app.grpcRecoverPanic, app.grpcAuthenticate, app.grpcRateLimit, app.grpcRequireActivatedUser))
+	parkourv1.RegisterMovementServiceServer(srv, &movementServer{app: app})
+	parkourv1.RegisterUserServiceServer(srv, &userServer{app: app})
+	reflection.Register(srv)
+	return srv
+}
+
+// Interceptor that turns a panic in a method into an INTERNAL error, like recoverPanic
+func (app *application) grpcRecoverPanic(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			app.logger.PrintError(fmt.Errorf("%v", p), map[string]string{"grpc_method": info.FullMethod})
+			err = status.Error(codes.Internal, serverErrorMessage)
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// Interceptor that reads the bearer token of the authorization metadata, like authenticate
+// The calls without one go through as the anonymous user
+func (app *application) grpcAuthenticate(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	user := data.AnonymousUser
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get("authorization"); len(values) > 0 {
+		// Check that the authentication type is "Bearer" and the token is properly formed
+		headerParts := strings.Split(values[0], " ")
+		if len(headerParts) != 2 || headerParts[0] != "Bearer" {
+			return nil, status.Error(codes.Unauthenticated, "missing or invalid authentication token")
+		}
+
+		token := headerParts[1]
+		v := validator.NewValidator()
+		if data.ValidateTokenPlainText(v, token); !v.NoErrors() {
+			return nil, status.Error(codes.Unauthenticated, "missing or invalid authentication token")
+		}
+
+		var err error
+		user, err = app.models.Users.GetUserFromToken(ctx, data.ScopeAuthentication, token)
+		if err != nil {
+			if errors.Is(err, data.ErrNotFound) {
+				return nil, status.Error(codes.Unauthenticated, "missing or invalid authentication token")
+			}
+			return nil, app.grpcError(ctx, err)
+		}
+	}
+
+	return handler(context.WithValue(ctx, userContextKey, user), req)
+}
+
+// Interceptor that limits the rate of calls a client can make to a method, like rateLimit
+// Clients are told apart by their user if grpcAuthenticate has identified one, else by their IP address
+// The state of the limit is sent back in the header metadata, under the same names as the HTTP headers
+func (app *application) grpcRateLimit(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if !app.config.limiter.enabled {
+		return handler(ctx, req)
+	}
+
+	route, exists := grpcMethodLimits[info.FullMethod]
+	if !exists {
+		route = globalLimit
+	}
+	name, limit := app.routeLimit(route)
+
+	client := "ip:" + grpcClientIP(ctx)
+	if user := grpcUser(ctx); !user.IsAnonymous() {
+		client = fmt.Sprintf("user:%d", user.ID)
+	}
+
+	result, err := app.limiter.Allow(ctx, name+"|"+client, limit)
+	if err != nil {
+		// Don't lock everybody out because the limiter is failing
+		app.logger.PrintError(fmt.Errorf("rate limiter: %w", err), map[string]string{"grpc_method": info.FullMethod})
+		return handler(ctx, req)
+	}
+
+	md := metadata.Pairs(
+		"ratelimit-limit", strconv.Itoa(result.Limit),
+		"ratelimit-remaining", strconv.Itoa(result.Remaining),
+		"ratelimit-reset", strconv.Itoa(ceilSeconds(result.Reset)),
+	)
+
+	if !result.Allowed {
+		// Never tell the client to retry straight away, it would only be limited again
+		seconds := ceilSeconds(result.RetryAfter)
+		if seconds < 1 {
+			seconds = 1
+		}
+		md.Set("retry-after", strconv.Itoa(seconds))
+		grpc.SetHeader(ctx, md)
+		return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded, please slow down")
+	}
+
+	grpc.SetHeader(ctx, md)
+	return handler(ctx, req)
+}
+
+// Interceptor that refuses the calls to grpcActivatedMethods without an activated user, like requireActivatedUser
+func (app *application) grpcRequireActivatedUser(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if grpcActivatedMethods[info.FullMethod] {
+		user := grpcUser(ctx)
+		if user.IsAnonymous() {
+			return nil, status.Error(codes.Unauthenticated, "you are not authenticated for this action")
+		}
+		if !user.Activated {
+			return nil, status.Error(codes.PermissionDenied, "user must be activated for this action")
+		}
+	}
+
+	return handler(ctx, req)
+}
+
+// Function that returns the IP address of the client making the call
+// Unlike clientIP the x-forwarded-for metadata is never used, the gRPC port is not behind
+// the proxies of -trusted-proxies and a direct caller could put any address in it
+func grpcClientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	ip, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return ip
+}
+
+// Function that returns the user that grpcAuthenticate has put in the context
+func grpcUser(ctx context.Context) *data.User {
+	user, ok := ctx.Value(userContextKey).(*data.User)
+	if !ok {
+		panic("missing user value in grpc context")
+	}
+	return user
+}
+
+// Method on the app instance that returns the gRPC status of an error of the models
+// It maps the errors the same way as the REST error responses, and the details
+// of the server errors are logged but never sent
+func (app *application) grpcError(ctx context.Context, err error) error {
+	switch {
+	case errors.Is(err, data.ErrNotFound):
+		return status.Error(codes.NotFound, "the resource could not be found")
+	case errors.Is(err, data.ErrEditConflict):
+		return status.Error(codes.Aborted, "unable to update the record due an edit conflict, please try again")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, "the request was cancelled")
+	case data.IsCanceled(err):
+		return status.Error(codes.DeadlineExceeded, "the server took too long to process your request, please try again")
+	default:
+		method, _ := grpc.Method(ctx)
+		app.logger.PrintError(err, map[string]string{"grpc_method": method})
+		return status.Error(codes.Internal, serverErrorMessage)
+	}
+}
+
+// Function that returns an INVALID_ARGUMENT error with the validation errors as field violations
+func grpcValidationError(errs map[string]string) error {
+	fields := make([]string, 0, len(errs))
+	for field := range errs {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	badRequest := &errdetails.BadRequest{}
+	for _, field := range fields {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: errs[field],
+		})
+	}
+
+	st := status.New(codes.InvalidArgument, "failed validation")
+	detailed, err := st.WithDetails(badRequest)
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// Function that returns the protobuf message of a movement
+func toProtoMovement(movement *data.Movement) *parkourv1.Movement {
+	return &parkourv1.Movement{
+		Id:            movement.ID,
+		Name:          movement.Name,
+		Description:   movement.Description,
+		Image:         movement.Image,
+		Tutorials:     movement.Tutorials,
+		Skilltype:     movement.Skilltype,
+		Muscles:       movement.Muscles,
+		Difficulty:    movement.Difficulty,
+		Equipments:    movement.Equipments,
+		Prerequisites: movement.Prerequisites,
+		Version:       movement.Version,
+		CreatorId:     movement.CreatorID,
+		VariationOf:   movement.VariationOf,
+	}
+}
+
+// Function that copies the input of CreateMovement or UpdateMovement to a movement
+func copyMovementInput(movement *data.Movement, input *parkourv1.MovementInput) {
+	movement.Name = input.GetName()
+	movement.Description = input.GetDescription()
+	movement.Image = input.GetImage()
+	movement.Tutorials = input.GetTutorials()
+	movement.Skilltype = input.GetSkilltype()
+	movement.Muscles = input.GetMuscles()
+	movement.Difficulty = input.GetDifficulty()
+	movement.Equipments = input.GetEquipments()
+	movement.Prerequisites = input.GetPrerequisites()
+	movement.VariationOf = input.VariationOf
+}
+
+// Function that returns the protobuf message of a user
+func toProtoUser(user *data.User) *parkourv1.User {
+	return &parkourv1.User{
+		Id:        user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		Activated: user.Activated,
+	}
+}
+
+// Function that returns the values of a repeated filter, empty rather than nil
+// The queries treat an empty array as "any", which a nil one is not
+func filterValues(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// movementServer implements the MovementService over the same models as the handlers of movements.go
+type movementServer struct {
+	parkourv1.UnimplementedMovementServiceServer
+	app *application
+}
+
+func (s *movementServer) ListMovements(ctx context.Context, req *parkourv1.ListMovementsRequest) (*parkourv1.ListMovementsResponse, error) {
+	// The unset fields get the same defaults as the query parameters
+	filters := data.Filters{
+		Sort:           req.GetSort(),
+		Page:           int(req.GetPage()),
+		PageSize:       int(req.GetPageSize()),
+		SortSafeList:   []string{"id", "name", "difficulty", "-name", "-difficulty"},
+		FieldsSafeList: data.MovementFields,
+	}
+	if filters.Sort == "" {
+		filters.Sort = "id"
+	}
+	if filters.Page == 0 {
+		filters.Page = 1
+	}
+	if filters.PageSize == 0 {
+		filters.PageSize = 20
+	}
+
+	v := validator.NewValidator()
+	if data.ValidateFilters(v, filters); !v.NoErrors() {
+		return nil, grpcValidationError(v.Errors)
+	}
+
+	movements, err := s.app.models.Movements.GetAllMovements(
+		ctx, req.GetName(), req.GetDifficulty(), filterValues(req.GetSkilltype()),
+		filterValues(req.GetMuscles()), filterValues(req.GetEquipments()), filters,
+	)
+	if err != nil {
+		return nil, s.app.grpcError(ctx, err)
+	}
+
+	resp := &parkourv1.ListMovementsResponse{Movements: make([]*parkourv1.Movement, len(movements))}
+	for i, movement := range movements {
+		resp.Movements[i] = toProtoMovement(movement)
+	}
+	return resp, nil
+}
+
+func (s *movementServer) GetMovement(ctx context.Context, req *parkourv1.GetMovementRequest) (*parkourv1.GetMovementResponse, error) {
+	if req.GetId() < 1 {
+		return nil, status.Error(codes.NotFound, "the resource could not be found")
+	}
+
+	movement, err := s.app.models.Movements.GetOneMovement(ctx, req.GetId())
+	if err != nil {
+		return nil, s.app.grpcError(ctx, err)
+	}
+	return &parkourv1.GetMovementResponse{Movement: toProtoMovement(movement)}, nil
+}
+
+func (s *movementServer) CreateMovement(ctx context.Context, req *parkourv1.CreateMovementRequest) (*parkourv1.CreateMovementResponse, error) {
+	if req.GetMovement() == nil {
+		return nil, grpcValidationError(map[string]string{"movement": "must be provided"})
+	}
+
+	movement := &data.Movement{CreatorID: &grpcUser(ctx).ID}
+	copyMovementInput(movement, req.GetMovement())
+
+	v := validator.NewValidator()
+	data.ValidateMovement(v, movement)
+	err := s.app.validateVariationOf(ctx, v, movement)
+	if err != nil {
+		return nil, s.app.grpcError(ctx, err)
+	}
+	if !v.NoErrors() {
+		return nil, grpcValidationError(v.Errors)
+	}
+
+	err = s.app.models.Movements.InsertOneMovement(ctx, movement)
+	if err != nil {
+		return nil, s.app.grpcError(ctx, err)
+	}
+	return &parkourv1.CreateMovementResponse{Movement: toProtoMovement(movement)}, nil
+}
+
+func (s *movementServer) UpdateMovement(ctx context.Context, req *parkourv1.UpdateMovementRequest) (*parkourv1.UpdateMovementResponse, error) {
+	if req.GetId() < 1 {
+		return nil, status.Error(codes.NotFound, "the resource could not be found")
+	}
+	if req.GetMovement() == nil {
+		return nil, grpcValidationError(map[string]string{"movement": "must be provided"})
+	}
+
+	movement, err := s.app.models.Movements.GetOneMovement(ctx, req.GetId())
+	if err != nil {
+		return nil, s.app.grpcError(ctx, err)
+	}
+
+	// The update is refused if the movement has changed since the version the client read
+	if req.Version != nil && *req.Version != movement.Version {
+		return nil, s.app.grpcError(ctx, data.ErrEditConflict)
+	}
+
+	copyMovementInput(movement, req.GetMovement())
+
+	v := validator.NewValidator()
+	data.ValidateMovement(v, movement)
+	err = s.app.validateVariationOf(ctx, v, movement)
+	if err != nil {
+		return nil, s.app.grpcError(ctx, err)
+	}
+	if !v.NoErrors() {
+		return nil, grpcValidationError(v.Errors)
+	}
+
+	err = s.app.models.Movements.UpdateOneMovement(ctx, movement)
+	if err != nil {
+		return nil, s.app.grpcError(ctx, err)
+	}
+	return &parkourv1.UpdateMovementResponse{Movement: toProtoMovement(movement)}, nil
+}
+
+func (s *movementServer) DeleteMovement(ctx context.Context, req *parkourv1.DeleteMovementRequest) (*parkourv1.DeleteMovementResponse, error) {
+	if req.GetId() < 1 {
+		return nil, status.Error(codes.NotFound, "the resource could not be found")
+	}
+
+	err := s.app.models.Movements.DeleteOneMovement(ctx, req.GetId())
+	if err != nil {
+		return nil, s.app.grpcError(ctx, err)
+	}
+	return &parkourv1.DeleteMovementResponse{}, nil
+}
+
+func (s *movementServer) GetMovementChanges(ctx context.Context, req *parkourv1.GetMovementChangesRequest) (*parkourv1.GetMovementChangesResponse, error) {
+	since, err := data.DecodeSyncToken(req.GetSince())
+	if err != nil {
+		return nil, grpcValidationError(map[string]string{"since": "must be a valid sync token"})
+	}
+
+	changes, err := s.app.models.Movements.GetMovementChanges(ctx, since)
+	if err != nil {
+		return nil, s.app.grpcError(ctx, err)
+	}
+
+	return &parkourv1.GetMovementChangesResponse{
+		Inserted:  changes.Inserted,
+		Updated:   changes.Updated,
+		Deleted:   changes.Deleted,
+		NextToken: changes.NextToken,
+	}, nil
+}
+
+// userServer implements the UserService over the same models as the handlers of users.go
+type userServer struct {
+	parkourv1.UnimplementedUserServiceServer
+	app *application
+}
+
+func (s *userServer) RegisterUser(ctx context.Context, req *parkourv1.RegisterUserRequest) (*parkourv1.RegisterUserResponse, error) {
+	user := &data.User{
+		Username:  req.GetUsername(),
+		Email:     req.GetEmail(),
+		Activated: false,
+	}
+
+	err := user.Password.SetHash(req.GetPassword())
+	if err != nil {
+		return nil, s.app.grpcError(ctx, err)
+	}
+
+	v := validator.NewValidator()
+	if data.ValidateUser(v, user); !v.NoErrors() {
+		return nil, grpcValidationError(v.Errors)
+	}
+
+	// Insert the user and generate their activation token in a single transaction, like registerUserHandler
+	var token *data.Token
+	err = s.app.models.Transaction(ctx, func(tx data.Models) error {
+		err := tx.Users.InsertOneUser(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		token, err = tx.Tokens.NewToken(ctx, user.ID, 2*24*time.Hour, data.ScopeActivation)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, data.ErrDuplicateEmail) {
+			return nil, grpcValidationError(map[string]string{"email": "must be unique"})
+		}
+		return nil, s.app.grpcError(ctx, err)
+	}
+	s.app.metrics.registrations.Inc()
+
+	return &parkourv1.RegisterUserResponse{User: toProtoUser(user), ActivationToken: token.PlainText}, nil
+}
+
+func (s *userServer) ActivateUser(ctx context.Context, req *parkourv1.ActivateUserRequest) (*parkourv1.ActivateUserResponse, error) {
+	v := validator.NewValidator()
+	if data.ValidateTokenPlainText(v, req.GetToken()); !v.NoErrors() {
+		return nil, grpcValidationError(v.Errors)
+	}
+
+	user, err := s.app.models.Users.GetUserFromToken(ctx, data.ScopeActivation, req.GetToken())
+	if err != nil {
+		if errors.Is(err, data.ErrNotFound) {
+			return nil, grpcValidationError(map[string]string{"token": "invalid or expired activation token"})
+		}
+		return nil, s.app.grpcError(ctx, err)
+	}
+
+	// Activate the user and delete all their activation tokens in a single transaction, like activateUserHandler
+	version := user.Version
+	err = s.app.models.Transaction(ctx, func(tx data.Models) error {
+		user.Activated = true
+		user.Version = version
+
+		err := tx.Users.UpdateOneUser(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		return tx.Tokens.DeleteTokens(ctx, user.ID, data.ScopeActivation)
+	})
+	if err != nil {
+		return nil, s.app.grpcError(ctx, err)
+	}
+	s.app.metrics.activations.Inc()
+
+	return &parkourv1.ActivateUserResponse{User: toProtoUser(user)}, nil
+}
+
+func (s *userServer) Login(ctx context.Context, req *parkourv1.LoginRequest) (*parkourv1.LoginResponse, error) {
+	v := validator.NewValidator()
+	data.ValidateEmail(v, req.GetEmail())
+	data.ValidatePlainPassword(v, req.GetPassword())
+	if !v.NoErrors() {
+		return nil, grpcValidationError(v.Errors)
+	}
+
+	invalidCredentials := status.Error(codes.Unauthenticated, "invalid authentication credentials")
+
+	user, err := s.app.models.Users.GetOneUserByEmail(ctx, req.GetEmail())
+	if err != nil {
+		if errors.Is(err, data.ErrNotFound) {
+			s.app.metrics.logins.WithLabelValues("failure").Inc()
+			return nil, invalidCredentials
+		}
+		return nil, s.app.grpcError(ctx, err)
+	}
+
+	match, err := user.Password.Matchhash(req.GetPassword())
+	if err != nil {
+		return nil, s.app.grpcError(ctx, err)
+	}
+	if !match {
+		s.app.metrics.logins.WithLabelValues("failure").Inc()
+		return nil, invalidCredentials
+	}
+
+	token, err := s.app.models.Tokens.NewToken(ctx, user.ID, (24*30)*time.Hour, data.ScopeAuthentication)
+	if err != nil {
+		return nil, s.app.grpcError(ctx, err)
+	}
+	s.app.metrics.logins.WithLabelValues("success").Inc()
+
+	return &parkourv1.LoginResponse{
+		AuthenticationToken: token.PlainText,
+		Expiry:              timestamppb.New(token.Expiry),
+		User:                toProtoUser(user),
+	}, nil
+}
@@ -18,7 +18,7 @@ func (app *application) healthcheckHandler(w http.ResponseWriter, r *http.Reques
 	}
 
 	// Create and send JSON frpm data as the response
-	err := app.writeJSON(w, envelope{"system_info": env}, http.StatusOK, nil)
+	err := app.writeJSON(w, r, envelope{"system_info": env}, http.StatusOK, nil)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
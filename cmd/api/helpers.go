@@ -1,6 +1,9 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,6 +15,7 @@ import (
 
 	"github.com/arnab4477/Parkour_API/internal/validator"
 	"github.com/julienschmidt/httprouter"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Method on the app instance to retrieve the id parameter
@@ -30,13 +34,18 @@ func (app *application) readIDParam(ps httprouter.Params) (int64, error) {
 type envelope map[string]interface{}
 
 // Method pn the app instance to create and write JSON response, set status code and headers
-func (app *application) writeJSON(w http.ResponseWriter, data envelope, status int, header http.Header) error {
+func (app *application) writeJSON(w http.ResponseWriter, r *http.Request, data envelope, status int, header http.Header) error {
+	// The encoding gets a span of its own so that it can be told apart from the queries
+	_, span := app.tracer.Start(r.Context(), "writeJSON")
+	defer span.End()
 
 	// Create JSON from data
 	json, err := json.MarshalIndent(data, "", "\t")
 	if err != nil {
+		span.RecordError(err)
 		return err
 	}
+	span.SetAttributes(attribute.Int("bytes", len(json)))
 
 	// Append a new line at the end for better view
 	json = append(json, '\n')
@@ -54,10 +63,12 @@ func (app *application) writeJSON(w http.ResponseWriter, data envelope, status i
 	return nil
 }
 
+// The max size of a JSON request body
+const maxBodyBytes = 1_048_576
+
 func (app *application) readJSON(w http.ResponseWriter, r *http.Request, jsonInput interface{}) error {
 	// Set the max siz of the input
-	maxBytes := 1_048_576
-	r.Body = http.MaxBytesReader(w, r.Body, int64(maxBytes))
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
 
 	// Initialize the JSON decoder and restricting unknown fields
 	// This lines and the subsequest error codes need to be deleted if unknown fields are to be allowed
@@ -95,7 +106,7 @@ func (app *application) readJSON(w http.ResponseWriter, r *http.Request, jsonInp
 			return fmt.Errorf("body contains unknown key %s", unknownKey)
 
 		case err.Error() == "http: request body too large":
-			return fmt.Errorf("request body cannot be larger than %d bytes", maxBytes)
+			return fmt.Errorf("request body cannot be larger than %d bytes", maxBodyBytes)
 
 		case errors.As(err, &InvalidUnmarshalError):
 			panic(err)
@@ -164,3 +175,72 @@ func (app *application) readCsv(queries url.Values, key string, defaultValue []s
 
 	return strings.Split(stringValues, ",")
 }
+
+// Method that runs fn in a goroutine that the graceful shutdown waits for
+// A panic in fn is logged instead of crashing the whole server
+func (app *application) background(fn func()) {
+	app.wg.Add(1)
+
+	go func() {
+		defer app.wg.Done()
+
+		defer func() {
+			if err := recover(); err != nil {
+				app.logger.PrintError(fmt.Errorf("panic in background task: %v", err), nil)
+			}
+		}()
+
+		fn()
+	}()
+}
+
+// Method that tells the background loops, such as the sweeps of the rate limiter, to return
+func (app *application) stopBackground() {
+	app.stopOnce.Do(func() { close(app.stopping) })
+}
+
+// Method that stops the background loops and waits for all the background tasks until ctx is done
+// It reports false if they did not all finish in time
+func (app *application) waitBackground(ctx context.Context) bool {
+	app.stopBackground()
+
+	done := make(chan struct{})
+	go func() {
+		app.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Function that returns a new random request ID
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		// There is no good ID without randomness, but the request can still be served
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
+
+// Function that reports if a request ID sent by a client is safe to put in the logs and headers
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
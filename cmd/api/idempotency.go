@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/arnab4477/Parkour_API/internal/data"
+	"github.com/julienschmidt/httprouter"
+)
+
+// How long a key stays reserved while its request runs, so that a key whose request never finished,
+// such as when the server crashed, is free again soon. This is a little longer than the write timeout
+// of the server, after which the response of the request can't be sent anyway
+const idempotencyLease = time.Minute
+
+// The headers of a response that are stored with it and sent again when it is replayed
+// The rest, such as X-Request-ID and the rate limits, belong to the request that gets the replay
+var idempotentHeaders = []string{"Content-Type", "Location"}
+
+// The fields that are taken out of a JSON response before it is stored, at any depth
+// Like the tokens table the idempotency keys must not hold a token that can be used,
+// so the replay of a registration comes without its activation token
+var idempotencyRedactedFields = map[string]bool{"activationToken": true}
+
+// Function that reports if an Idempotency-Key is 1 to 255 visible ASCII characters
+func validIdempotencyKey(key string) bool {
+	if len(key) == 0 || len(key) > 255 {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < '!' || key[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// Middleware that runs a request with an Idempotency-Key only once within the idempotency window
+// A retry with the same key and the same request gets the stored response back, with an
+// Idempotent-Replayed header, and a different request with the same key gets a 409
+// Only the responses below 500 are stored, a retry after a server error runs the request again
+// The key is only reserved for a short lease while the request runs and kept for the whole
+// idempotency window once its response is stored
+// The fields of idempotencyRedactedFields are taken out of the stored responses
+func (app *application) idempotent(route string, next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" {
+			next(w, r, ps)
+			return
+		}
+		if !validIdempotencyKey(key) {
+			app.badRequestResponse(w, r, errors.New("the Idempotency-Key header must be 1 to 255 visible ASCII characters"))
+			return
+		}
+
+		// Read the body to fingerprint it and put it back for the handler
+		// A body over the limit is left to the handler, which refuses it anyway
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxBodyBytes+1))
+		if err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+		r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+		if len(body) > maxBodyBytes {
+			next(w, r, ps)
+			return
+		}
+
+		// The Accept header is part of the request, a retry asking for another format is a different request
+		hash := sha256.New()
+		io.WriteString(hash, r.Method+" "+r.URL.RequestURI()+"\n")
+		io.WriteString(hash, "Accept: "+r.Header.Get("Accept")+"\n")
+		hash.Write(body)
+
+		// The keys are scoped to the user, or to the IP address of the anonymous clients
+		scope := data.IdempotencyKey{Key: key, Route: r.Method + " " + route}
+		if user, ok := app.contextLookupUser(r); ok && !user.IsAnonymous() {
+			scope.UserID = user.ID
+		} else {
+			scope.Client = app.clientIP(r)
+		}
+
+		record := &data.IdempotencyRecord{
+			IdempotencyKey: scope,
+			Fingerprint:    hex.EncodeToString(hash.Sum(nil)),
+			ExpiresAt:      time.Now().Add(idempotencyLease),
+		}
+
+		existing, err := app.models.Idempotency.ReserveIdempotencyKey(r.Context(), record)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		if existing != nil {
+			switch {
+			case existing.Fingerprint != record.Fingerprint:
+				app.idempotencyKeyReusedResponse(w, r)
+			case !existing.Completed:
+				app.idempotencyKeyInUseResponse(w, r)
+			default:
+				for name, value := range existing.Header {
+					w.Header().Set(name, value)
+				}
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(existing.Status)
+				w.Write(existing.Body)
+			}
+			return
+		}
+
+		// The key is released if the request fails, even if the handler panics, so that a retry runs it again
+		// This happens after the request is over, so it must not depend on its context,
+		// which is cancelled if the client has gone away
+		rec := &idempotencyRecorder{ResponseWriter: w, status: http.StatusOK}
+		succeeded := false
+		defer func() {
+			if succeeded {
+				return
+			}
+			err := app.models.Idempotency.ReleaseIdempotencyKey(context.Background(), record.IdempotencyKey)
+			if err != nil {
+				app.logError(r, 0, err)
+			}
+		}()
+
+		next(rec, r, ps)
+
+		if rec.status >= http.StatusInternalServerError {
+			return
+		}
+		succeeded = true
+
+		record.Status = rec.status
+		record.Header = rec.header
+		record.Body, err = redactJSON(rec.header["Content-Type"], rec.body.Bytes())
+		if err != nil {
+			// A response that can't be redacted is not stored, the key stays reserved like below
+			app.logError(r, 0, err)
+			return
+		}
+		record.ExpiresAt = time.Now().Add(app.idempotencyTTL)
+		err = app.models.Idempotency.CompleteIdempotencyKey(context.Background(), record)
+		if err != nil {
+			// The request has been done already, so the key stays reserved rather than let a retry
+			// do it again, and the retries are told that the key is in use until the lease runs out
+			app.logError(r, 0, err)
+		}
+	}
+}
+
+// Method that deletes the expired idempotency keys every interval until the server stops
+// calictl idempotency purge does the same by hand
+func (app *application) sweepIdempotencyKeysEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			_, err := app.models.Idempotency.DeleteExpiredIdempotencyKeys(ctx)
+			cancel()
+			if err != nil {
+				app.logger.PrintError(fmt.Errorf("sweeping the idempotency keys: %w", err), nil)
+			}
+		case <-app.stopping:
+			return
+		}
+	}
+}
+
+// Function that returns a JSON body without the fields of idempotencyRedactedFields
+// The bodies of the other content types are returned as they are
+func redactJSON(contentType string, body []byte) ([]byte, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType != "application/json" || len(body) == 0 {
+		return body, nil
+	}
+
+	// Keep the numbers as they are written, a float64 could round the big IDs
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var value interface{}
+	err := dec.Decode(&value)
+	if err != nil {
+		return nil, err
+	}
+
+	if !redactFields(value) {
+		return body, nil
+	}
+
+	redacted, err := json.MarshalIndent(value, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	return append(redacted, '\n'), nil
+}
+
+// Function that deletes the fields of idempotencyRedactedFields from a decoded JSON value
+// It reports if any field was deleted
+func redactFields(value interface{}) bool {
+	redacted := false
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for name, field := range value {
+			if idempotencyRedactedFields[name] {
+				delete(value, name)
+				redacted = true
+				continue
+			}
+			if redactFields(field) {
+				redacted = true
+			}
+		}
+	case []interface{}:
+		for _, item := range value {
+			if redactFields(item) {
+				redacted = true
+			}
+		}
+	}
+	return redacted
+}
+
+// idempotencyRecorder sends the response on and keeps a copy of it to be stored
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	status      int
+	header      map[string]string
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (rec *idempotencyRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.wroteHeader = true
+		rec.status = status
+
+		rec.header = make(map[string]string)
+		for _, name := range idempotentHeaders {
+			if value := rec.Header().Get(name); value != "" {
+				rec.header[name] = value
+			}
+		}
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *idempotencyRecorder) Write(b []byte) (int, error) {
+	if !rec.wroteHeader {
+		rec.WriteHeader(http.StatusOK)
+	}
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
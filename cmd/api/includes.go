@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/arnab4477/Parkour_API/internal/data"
+	"github.com/arnab4477/Parkour_API/internal/validator"
+)
+
+// Constants for the relations of a movement that can be embedded with the include query parameter
+const (
+	includePrerequisites = "prerequisites"
+	includeVariations    = "variations"
+	includeCreator       = "creator"
+)
+
+// The relations in the order they are embedded in the responses
+var includeRelations = []string{includePrerequisites, includeVariations, includeCreator}
+
+// How many levels of related records can be embedded, prerequisites.prerequisites is two
+// Every level can multiply the size of the response, so this stays small
+const maxIncludeDepth = 3
+
+// includeTree holds the relations to embed and, under each of them, the relations to embed in the related records
+type includeTree map[string]includeTree
+
+// Function that reads paths such as include=prerequisites.creator,variations into a tree
+// The users have no relations of their own, so nothing can be embedded under the creator
+func parseIncludes(v *validator.Validator, paths []string) includeTree {
+	tree := includeTree{}
+	for _, path := range paths {
+		relations := strings.Split(path, ".")
+		if len(relations) > maxIncludeDepth {
+			v.AddError("include", "must not be nested more than 3 levels deep")
+			continue
+		}
+
+		node := tree
+		for i, relation := range relations {
+			if !validator.In(relation, includeRelations...) {
+				v.AddError("include", "invalid relation "+relation+", must be prerequisites, variations or creator")
+				break
+			}
+			if relation == includeCreator && i < len(relations)-1 {
+				v.AddError("include", "nothing can be included under creator")
+				break
+			}
+
+			if node[relation] == nil {
+				node[relation] = includeTree{}
+			}
+			node = node[relation]
+		}
+	}
+	return tree
+}
+
+// Method that returns the fields the movements need for the relations of the tree to be loaded
+// They are selected on top of the fields the client asked for, but only those are sent
+func (tree includeTree) requiredFields() []string {
+	var fields []string
+	if _, ok := tree[includePrerequisites]; ok {
+		fields = append(fields, "prerequisite")
+	}
+	if _, ok := tree[includeVariations]; ok {
+		fields = append(fields, "id")
+	}
+	if _, ok := tree[includeCreator]; ok {
+		fields = append(fields, "creator_id")
+	}
+	return fields
+}
+
+// Function that returns the fields with the extra ones added, or nil to select all of them
+func withFields(fields []string, extra []string) []string {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	merged := append([]string(nil), fields...)
+	for _, field := range extra {
+		if !validator.In(field, merged...) {
+			merged = append(merged, field)
+		}
+	}
+	return merged
+}
+
+// Method that embeds the related records of the tree in the views
+// Every relation is loaded with a single query for all the views of a level, never one per movement
+func (app *application) loadIncludes(ctx context.Context, views []*movementView, tree includeTree) error {
+	if len(views) == 0 || len(tree) == 0 {
+		return nil
+	}
+
+	if subtree, ok := tree[includePrerequisites]; ok {
+		err := app.loadPrerequisites(ctx, views, subtree)
+		if err != nil {
+			return err
+		}
+	}
+
+	if subtree, ok := tree[includeVariations]; ok {
+		err := app.loadVariations(ctx, views, subtree)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, ok := tree[includeCreator]; ok {
+		err := app.loadCreators(ctx, views)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Method that embeds the movements named in the prerequisites of the views
+// A prerequisite without a movement of that name is left out
+func (app *application) loadPrerequisites(ctx context.Context, views []*movementView, subtree includeTree) error {
+	seen := make(map[string]bool)
+	var names []string
+	for _, view := range views {
+		for _, name := range view.movement.Prerequisites {
+			if !seen[strings.ToLower(name)] {
+				seen[strings.ToLower(name)] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	byName := make(map[string]*data.Movement)
+	if len(names) > 0 {
+		movements, err := app.models.Movements.GetMovementsByName(ctx, names)
+		if err != nil {
+			return err
+		}
+		for _, movement := range movements {
+			byName[strings.ToLower(movement.Name)] = movement
+		}
+	}
+
+	var children []*movementView
+	for _, view := range views {
+		prerequisites := []*movementView{}
+		for _, name := range view.movement.Prerequisites {
+			if movement, ok := byName[strings.ToLower(name)]; ok {
+				prerequisites = append(prerequisites, &movementView{movement: movement})
+			}
+		}
+		view.include(includePrerequisites, prerequisites)
+		children = append(children, prerequisites...)
+	}
+
+	return app.loadIncludes(ctx, children, subtree)
+}
+
+// Method that embeds the movements that are variations of the views
+func (app *application) loadVariations(ctx context.Context, views []*movementView, subtree includeTree) error {
+	ids := make([]int64, len(views))
+	for i, view := range views {
+		ids[i] = view.movement.ID
+	}
+
+	movements, err := app.models.Movements.GetVariations(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	byParent := make(map[int64][]*data.Movement)
+	for _, movement := range movements {
+		byParent[*movement.VariationOf] = append(byParent[*movement.VariationOf], movement)
+	}
+
+	var children []*movementView
+	for _, view := range views {
+		variations := newMovementViews(byParent[view.movement.ID], nil)
+		view.include(includeVariations, variations)
+		children = append(children, variations...)
+	}
+
+	return app.loadIncludes(ctx, children, subtree)
+}
+
+// Method that embeds the users who added the movements of the views
+// The creator is null for the movements that were imported or whose user is gone
+func (app *application) loadCreators(ctx context.Context, views []*movementView) error {
+	seen := make(map[int64]bool)
+	var ids []int64
+	for _, view := range views {
+		if id := view.movement.CreatorID; id != nil && !seen[*id] {
+			seen[*id] = true
+			ids = append(ids, *id)
+		}
+	}
+
+	byID := make(map[int64]*creatorView)
+	if len(ids) > 0 {
+		users, err := app.models.Users.GetUsersByID(ctx, ids)
+		if err != nil {
+			return err
+		}
+		for _, user := range users {
+			byID[user.ID] = &creatorView{ID: user.ID, Username: user.Username}
+		}
+	}
+
+	for _, view := range views {
+		var creator *creatorView
+		if id := view.movement.CreatorID; id != nil {
+			creator = byID[*id]
+		}
+		view.include(includeCreator, creator)
+	}
+	return nil
+}
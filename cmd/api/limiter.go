@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arnab4477/Parkour_API/internal/ratelimit"
+	"github.com/julienschmidt/httprouter"
+)
+
+// The name of the limit used by the routes that don't have a limit of their own
+const globalLimit = "global"
+
+// routeLimits type holds the per route limits given with the -limiter-route flag
+// It implements flag.Value so that the flag can be given more than once
+type routeLimits map[string]ratelimit.Limit
+
+// Method that returns the limits as they are written in the flag
+func (rl routeLimits) String() string {
+	names := make([]string, 0, len(rl))
+	for name := range rl {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	limits := make([]string, 0, len(names))
+	for _, name := range names {
+		limits = append(limits, fmt.Sprintf("%s=%g:%d", name, rl[name].Rate, rl[name].Burst))
+	}
+	return strings.Join(limits, ",")
+}
+
+// Method that parses a limit in the form name=rps:burst, such as login=0.1:5
+func (rl routeLimits) Set(value string) error {
+	name, limit, found := strings.Cut(value, "=")
+	if !found || name == "" {
+		return fmt.Errorf("route limit must be in the form name=rps:burst")
+	}
+
+	rate, burst, found := strings.Cut(limit, ":")
+	if !found {
+		return fmt.Errorf("route limit must be in the form name=rps:burst")
+	}
+
+	rps, err := strconv.ParseFloat(rate, 64)
+	if err != nil || rps <= 0 || math.IsInf(rps, 0) {
+		return fmt.Errorf("invalid requests per second %q for route %q", rate, name)
+	}
+	b, err := strconv.Atoi(burst)
+	if err != nil || b < 1 {
+		return fmt.Errorf("invalid burst %q for route %q", burst, name)
+	}
+
+	rl[name] = ratelimit.Limit{Rate: rps, Burst: b}
+	return nil
+}
+
+// Method that returns the limit of a route, or the global limit if the route has none
+// The routes without a limit of their own all share the global bucket of a client
+func (app *application) routeLimit(route string) (string, ratelimit.Limit) {
+	if limit, exists := app.config.limiter.routes[route]; exists {
+		return route, limit
+	}
+	return globalLimit, ratelimit.Limit{Rate: app.config.limiter.rps, Burst: app.config.limiter.burst}
+}
+
+// Method that returns how long a bucket can be idle before it is swept
+// By then every bucket has refilled, so sweeping it does not reset anybody's limit early
+func (app *application) limiterIdleTime() time.Duration {
+	idle := 3 * time.Minute
+
+	limits := []ratelimit.Limit{{Rate: app.config.limiter.rps, Burst: app.config.limiter.burst}}
+	for _, limit := range app.config.limiter.routes {
+		limits = append(limits, limit)
+	}
+	for _, limit := range limits {
+		refill := time.Duration(float64(limit.Burst) / limit.Rate * float64(time.Second))
+		if refill > idle {
+			idle = refill
+		}
+	}
+	return idle
+}
+
+// Method that returns the IP address of the client making the request
+// Behind -trusted-proxies proxies it is the X-Forwarded-For entry added by the outermost of them
+// The entries on its left are sent by the client, which could send a new address with every request
+func (app *application) clientIP(r *http.Request) string {
+	if ip, ok := forwardedFor(r.Header.Values("X-Forwarded-For"), app.config.trustedProxies); ok {
+		return ip
+	}
+
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
+
+// Function that returns the X-Forwarded-For entry added by the outermost of hops trusted proxies
+// Every proxy appends the address it got the request from, so that entry is hops positions from the right
+// It reports false when the header is not to be used, or has fewer entries than there are proxies
+func forwardedFor(values []string, hops int) (string, bool) {
+	if hops < 1 {
+		return "", false
+	}
+
+	var entries []string
+	for _, value := range values {
+		for _, entry := range strings.Split(value, ",") {
+			entries = append(entries, strings.TrimSpace(entry))
+		}
+	}
+
+	if len(entries) < hops {
+		return "", false
+	}
+	ip := entries[len(entries)-hops]
+	if net.ParseIP(ip) == nil {
+		return "", false
+	}
+	return ip, true
+}
+
+// Middleware that limits the rate of requests a client can make to a route
+// Clients are told apart by their user if authenticate has identified one, else by their IP address
+func (app *application) rateLimit(route string, next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		if !app.config.limiter.enabled {
+			next(w, r, ps)
+			return
+		}
+
+		name, limit := app.routeLimit(route)
+
+		client := "ip:" + app.clientIP(r)
+		if user, ok := app.contextLookupUser(r); ok && !user.IsAnonymous() {
+			client = fmt.Sprintf("user:%d", user.ID)
+		}
+
+		result, err := app.limiter.Allow(r.Context(), name+"|"+client, limit)
+		if err != nil {
+			// Don't lock everybody out because the limiter is failing
+			app.logger.PrintError(fmt.Errorf("rate limiter: %w", err), app.requestProperties(r, 0))
+			next(w, r, ps)
+			return
+		}
+
+		// Let the client know where it stands, see draft-ietf-httpapi-ratelimit-headers
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+		if !result.Allowed {
+			app.rateLimitExceededResponse(w, r, result.RetryAfter)
+			return
+		}
+
+		next(w, r, ps)
+	}
+}
+
+// Function that rounds a duration up to whole seconds, as the headers don't take fractions
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/arnab4477/Parkour_API/internal/data"
+)
+
+// batchLoader collects the keys that the GraphQL resolvers ask for and fetches all of them
+// with a single call once the first of their thunks runs
+// The thunks of a level of the query only run after every resolver of that level has,
+// so a list of 20 movements asking for their creator makes one query, not 20
+type batchLoader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	pending []K
+	queued  map[K]bool
+	results map[K]V
+	errs    map[K]error
+}
+
+// This returns a loader fetching its keys with fetch
+// A key that fetch leaves out of its map gets the zero value
+func newBatchLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *batchLoader[K, V] {
+	return &batchLoader[K, V]{
+		fetch:   fetch,
+		queued:  make(map[K]bool),
+		results: make(map[K]V),
+		errs:    make(map[K]error),
+	}
+}
+
+// Method that queues the key and returns a thunk that returns its value
+func (l *batchLoader[K, V]) load(ctx context.Context, key K) func() (V, error) {
+	l.mu.Lock()
+	if !l.queued[key] {
+		l.queued[key] = true
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if _, done := l.results[key]; !done && l.errs[key] == nil {
+			l.flush(ctx)
+		}
+		return l.results[key], l.errs[key]
+	}
+}
+
+// Method that fetches every pending key, it must be called with mu held
+func (l *batchLoader[K, V]) flush(ctx context.Context) {
+	keys := l.pending
+	l.pending = nil
+
+	found, err := l.fetch(ctx, keys)
+	for _, key := range keys {
+		if err != nil {
+			l.errs[key] = err
+			continue
+		}
+		l.results[key] = found[key]
+	}
+}
+
+// graphqlLoaders holds the loaders of a GraphQL request
+// They cache what they fetch, so they must never outlive the request
+type graphqlLoaders struct {
+	movements     *batchLoader[int64, *data.Movement]
+	movementNames *batchLoader[string, *data.Movement] // The keys are the lowercased names
+	variations    *batchLoader[int64, []*data.Movement]
+	users         *batchLoader[int64, *data.User]
+}
+
+// Method on the app instance that returns new loaders over the models
+func (app *application) newGraphQLLoaders() *graphqlLoaders {
+	return &graphqlLoaders{
+		movements: newBatchLoader(func(ctx context.Context, ids []int64) (map[int64]*data.Movement, error) {
+			movements, err := app.models.Movements.GetMovementsByID(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			byID := make(map[int64]*data.Movement, len(movements))
+			for _, movement := range movements {
+				byID[movement.ID] = movement
+			}
+			return byID, nil
+		}),
+
+		movementNames: newBatchLoader(func(ctx context.Context, names []string) (map[string]*data.Movement, error) {
+			movements, err := app.models.Movements.GetMovementsByName(ctx, names)
+			if err != nil {
+				return nil, err
+			}
+			byName := make(map[string]*data.Movement, len(movements))
+			for _, movement := range movements {
+				byName[strings.ToLower(movement.Name)] = movement
+			}
+			return byName, nil
+		}),
+
+		variations: newBatchLoader(func(ctx context.Context, ids []int64) (map[int64][]*data.Movement, error) {
+			movements, err := app.models.Movements.GetVariations(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			byParent := make(map[int64][]*data.Movement)
+			for _, movement := range movements {
+				byParent[*movement.VariationOf] = append(byParent[*movement.VariationOf], movement)
+			}
+			return byParent, nil
+		}),
+
+		users: newBatchLoader(func(ctx context.Context, ids []int64) (map[int64]*data.User, error) {
+			users, err := app.models.Users.GetUsersByID(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			byID := make(map[int64]*data.User, len(users))
+			for _, user := range users {
+				byID[user.ID] = user
+			}
+			return byID, nil
+		}),
+	}
+}
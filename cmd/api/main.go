@@ -3,14 +3,22 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
-	"net/http"
 	"os"
+	"sync"
 	"time"
 
+	"github.com/arnab4477/Parkour_API/internal/accesslog"
 	"github.com/arnab4477/Parkour_API/internal/data"
+	"github.com/arnab4477/Parkour_API/internal/jsonlog"
+	"github.com/arnab4477/Parkour_API/internal/migrate"
+	"github.com/arnab4477/Parkour_API/internal/ratelimit"
+	"github.com/arnab4477/Parkour_API/internal/validator"
+	"github.com/arnab4477/Parkour_API/migrations"
+	"go.opentelemetry.io/otel/trace"
 
 	_ "github.com/lib/pq"
 )
@@ -19,21 +27,94 @@ const version = "1.0.0"
 
 // The config struct
 type config struct {
-	port int
-	env  string
-	db   struct {
+	port    int
+	env     string
+	migrate string
+	storage string
+	log     struct {
+		level  string
+		format string
+	}
+	accessLog struct {
+		output     string
+		format     string
+		maxSize    int
+		maxBackups int
+		samples    sampleRates
+	}
+	// Grace period for the in-flight requests and background tasks when shutting down
+	shutdownTimeout string
+	tracing         struct {
+		exporter    string
+		endpoint    string
+		insecure    bool
+		sampleRatio float64
+	}
+	cors struct {
+		trustedOrigins   trustedOrigins
+		allowCredentials bool
+		maxAge           string
+	}
+	compression struct {
+		enabled bool
+		minSize int
+	}
+	cache struct {
+		enabled bool
+		size    int
+		ttl     string
+	}
+	// Limits of the GraphQL queries, checked before they run
+	graphql struct {
+		maxDepth int
+		maxCost  int
+	}
+	// Window in which the retries with the same Idempotency-Key get the stored response
+	idempotency struct {
+		ttl string
+	}
+	// Address of the admin listener for the metrics, empty to disable it
+	adminAddr string
+	// Port of the gRPC services, 0 to disable them
+	grpcPort int
+	// Number of proxies in front of the API whose X-Forwarded-For entries are trusted, 0 to use the connection address
+	trustedProxies int
+	limiter        struct {
+		enabled bool
+		store   string
+		timeout string
+		rps     float64
+		burst   int
+		routes  routeLimits
+	}
+	db struct {
 		dsn          string
 		maxOpenConns int
 		maxIdleConns int
 		maxIdletime  string
+		queryTimeout string
 	}
 }
 
 // The application struct
 type application struct {
-	config config
-	logger *log.Logger
-	models data.Models
+	config    config
+	logger    *jsonlog.Logger
+	accessLog *accesslog.Logger
+	metrics   *metrics
+	tracer    trace.Tracer
+	// How long the browsers can cache the result of a pre-flight request
+	corsMaxAge time.Duration
+	// How long the responses to the requests with an Idempotency-Key are kept
+	idempotencyTTL time.Duration
+	models         data.Models
+	limiter        ratelimit.Limiter
+	wg             sync.WaitGroup
+	// Closed once the server stops serving, the background loops return when it is
+	stopping chan struct{}
+	stopOnce sync.Once
+	// The routes registered by routes()
+	endpoints []endpoint
 }
 
 func main() {
@@ -48,42 +129,249 @@ func main() {
 	flag.IntVar(&cfg.db.maxOpenConns, "db-max-open-conns", 25, "PostgreSQL max open connetions")
 	flag.IntVar(&cfg.db.maxIdleConns, "db-max-idle-conns", 25, "PostgreSQL max idle connetions")
 	flag.StringVar(&cfg.db.maxIdletime, "db-max-idle-time", "15m", "PostgreSQL max connection idle time")
-	flag.Parse()
+	flag.StringVar(&cfg.db.queryTimeout, "db-query-timeout", "5s", "PostgreSQL max time for a single query (0 to disable)")
+	flag.StringVar(&cfg.migrate, "migrate", "up", "Database migrations to run at startup (up | none | status)")
+	flag.StringVar(&cfg.storage, "storage", "postgres", "Storage backend (postgres | memory)")
+	flag.StringVar(&cfg.shutdownTimeout, "shutdown-timeout", "20s", "Grace period for in-flight requests and background tasks on shutdown")
+	flag.StringVar(&cfg.log.level, "log-level", "info", "Minimum level of the logs (debug | info | error | fatal | off)")
+	flag.StringVar(&cfg.log.format, "log-format", jsonlog.FormatJSON, "Format of the logs (json | text)")
 
-	// Logger function for customized logging
-	logger := log.New(os.Stdout, "", log.Ldate|log.Ltime)
+	// Access log flags
+	cfg.accessLog.samples = sampleRates{}
+	flag.StringVar(&cfg.accessLog.output, "access-log", "stdout", "Where the access log is written (stdout | off | a file path)")
+	flag.StringVar(&cfg.accessLog.format, "access-log-format", accesslog.FormatJSON, "Format of the access log (common | combined | json)")
+	flag.IntVar(&cfg.accessLog.maxSize, "access-log-max-size", 100, "Size in megabytes at which the access log file is rotated")
+	flag.IntVar(&cfg.accessLog.maxBackups, "access-log-max-backups", 5, "Number of rotated access log files to keep")
+	flag.Var(cfg.accessLog.samples, "access-log-sample", "Share of the successful requests logged for a path prefix as /prefix=rate, can be repeated")
 
-	// Create a database connection pool
-	db, err := openDB(cfg)
+	flag.StringVar(&cfg.tracing.exporter, "otel-exporter", "none", "Where the traces are exported (none | stdout | otlp)")
+	flag.StringVar(&cfg.tracing.endpoint, "otel-endpoint", "", "OTLP/HTTP collector host:port, OTEL_EXPORTER_OTLP_ENDPOINT or localhost:4318 if empty")
+	flag.BoolVar(&cfg.tracing.insecure, "otel-insecure", false, "Send the traces to the OTLP collector over plain HTTP")
+	flag.Float64Var(&cfg.tracing.sampleRatio, "otel-sample-ratio", 1, "Share of the traces started by the API that are recorded")
+	// CORS flags, every origin is trusted by default but without credentials
+	cfg.cors.trustedOrigins = trustedOrigins{"*"}
+	flag.Var(&cfg.cors.trustedOrigins, "cors-trusted-origins", "Trusted CORS origins separated by spaces, such as \"https://app.example.com https://*.example.com\"")
+	flag.BoolVar(&cfg.cors.allowCredentials, "cors-allow-credentials", true, "Allow credentialed CORS requests from the trusted origins, never from \"*\"")
+	flag.StringVar(&cfg.cors.maxAge, "cors-max-age", "1h", "How long the browsers can cache a CORS pre-flight response")
+	flag.BoolVar(&cfg.compression.enabled, "compression-enabled", true, "Compress the responses with brotli or gzip")
+	flag.IntVar(&cfg.compression.minSize, "compression-min-size", 1024, "Smallest response body in bytes that is compressed")
+	flag.BoolVar(&cfg.cache.enabled, "cache-enabled", true, "Cache the movement lookups in memory")
+	flag.IntVar(&cfg.cache.size, "cache-size", 1000, "Max number of movement lookups kept in the cache")
+	flag.StringVar(&cfg.cache.ttl, "cache-ttl", "1m", "How long a movement lookup is kept in the cache")
+	flag.IntVar(&cfg.graphql.maxDepth, "graphql-max-depth", 6, "Max nesting of the fields of a GraphQL query (0 to disable)")
+	flag.IntVar(&cfg.graphql.maxCost, "graphql-max-cost", 5000, "Max estimated number of fields a GraphQL query can resolve (0 to disable)")
+	flag.StringVar(&cfg.idempotency.ttl, "idempotency-ttl", "24h", "How long a response is replayed to the retries with the same Idempotency-Key")
+	flag.IntVar(&cfg.grpcPort, "grpc-port", 7003, "The gRPC port (0 to disable)")
+	flag.StringVar(&cfg.adminAddr, "admin-addr", "localhost:7002", "Address of the admin listener serving /metrics, empty to disable it")
+	flag.IntVar(&cfg.trustedProxies, "trusted-proxies", 0, "Number of proxies in front of the API, the client IP address is the X-Forwarded-For entry the outermost one added (0 to ignore the header)")
+	trustProxy := flag.Bool("trust-proxy", false, "Same as -trusted-proxies=1, for a single proxy in front of the API")
+
+	// Rate limiter flags, the login and registration routes are stricter by default
+	cfg.limiter.routes = routeLimits{
+		"login":    {Rate: 0.1, Burst: 5},
+		"register": {Rate: 0.05, Burst: 3},
+	}
+	flag.BoolVar(&cfg.limiter.enabled, "limiter-enabled", true, "Enable the rate limiter")
+	flag.StringVar(&cfg.limiter.store, "limiter-store", "memory", "Where the rate limits are kept (memory | postgres)")
+	flag.StringVar(&cfg.limiter.timeout, "limiter-timeout", "50ms", "Max time for the postgres rate limiter before falling back to local limits")
+	flag.Float64Var(&cfg.limiter.rps, "limiter-rps", 2, "Rate limiter max requests per second for each client")
+	flag.IntVar(&cfg.limiter.burst, "limiter-burst", 4, "Rate limiter max burst for each client")
+	flag.Var(cfg.limiter.routes, "limiter-route", "Rate limit for a single route as name=rps:burst, can be repeated")
+	flag.Parse()
+
+	// Structured logger for customized logging, until the flags are checked it logs with the defaults
+	logger := jsonlog.New(os.Stdout, jsonlog.LevelInfo, jsonlog.FormatJSON)
+	logLevel, err := jsonlog.ParseLevel(cfg.log.level)
 	if err != nil {
-		logger.Fatal(err)
+		logger.PrintFatal(err, nil)
+	}
+	if !validator.In(cfg.log.format, jsonlog.FormatJSON, jsonlog.FormatText) {
+		logger.PrintFatal(fmt.Errorf("invalid -log-format value %q, must be json or text", cfg.log.format), nil)
 	}
+	logger = jsonlog.New(os.Stdout, logLevel, cfg.log.format)
 
-	// Make sure the connection to the pool closes before the main()
-	defer db.Close()
-	logger.Printf("Database connecton establisted")
+	// Check the migrate, storage and query timeout flags before connecting to the database
+	queryTimeout, err := time.ParseDuration(cfg.db.queryTimeout)
+	if err != nil || queryTimeout < 0 {
+		logger.PrintFatal(fmt.Errorf("invalid -db-query-timeout value %q", cfg.db.queryTimeout), nil)
+	}
+	if !validator.In(cfg.migrate, "up", "none", "status") {
+		logger.PrintFatal(fmt.Errorf("invalid -migrate value %q, must be up, none or status", cfg.migrate), nil)
+	}
+	if !validator.In(cfg.storage, "postgres", "memory") {
+		logger.PrintFatal(fmt.Errorf("invalid -storage value %q, must be postgres or memory", cfg.storage), nil)
+	}
+	if cfg.limiter.rps <= 0 || cfg.limiter.burst < 1 {
+		logger.PrintFatal(errors.New("invalid rate limiter values, -limiter-rps must be positive and -limiter-burst at least 1"), nil)
+	}
+	if !validator.In(cfg.limiter.store, "memory", "postgres") {
+		logger.PrintFatal(fmt.Errorf("invalid -limiter-store value %q, must be memory or postgres", cfg.limiter.store), nil)
+	}
+	if cfg.limiter.store == "postgres" && cfg.storage == "memory" {
+		logger.PrintFatal(errors.New("-limiter-store=postgres needs -storage=postgres"), nil)
+	}
+	limiterTimeout, err := time.ParseDuration(cfg.limiter.timeout)
+	if err != nil || limiterTimeout <= 0 {
+		logger.PrintFatal(fmt.Errorf("invalid -limiter-timeout value %q", cfg.limiter.timeout), nil)
+	}
+	corsMaxAge, err := time.ParseDuration(cfg.cors.maxAge)
+	if err != nil || corsMaxAge < 0 {
+		logger.PrintFatal(fmt.Errorf("invalid -cors-max-age value %q", cfg.cors.maxAge), nil)
+	}
+	cacheTTL, err := time.ParseDuration(cfg.cache.ttl)
+	if err != nil || cacheTTL <= 0 || cfg.cache.size < 1 {
+		logger.PrintFatal(errors.New("invalid cache values, -cache-ttl must be positive and -cache-size at least 1"), nil)
+	}
+	if cfg.trustedProxies < 0 {
+		logger.PrintFatal(fmt.Errorf("invalid -trusted-proxies value %d, must not be negative", cfg.trustedProxies), nil)
+	}
+	if *trustProxy && cfg.trustedProxies == 0 {
+		cfg.trustedProxies = 1
+	}
+	if cfg.graphql.maxDepth < 0 || cfg.graphql.maxCost < 0 {
+		logger.PrintFatal(errors.New("invalid graphql limits, -graphql-max-depth and -graphql-max-cost must not be negative"), nil)
+	}
+	if d, err := time.ParseDuration(cfg.shutdownTimeout); err != nil || d <= 0 {
+		logger.PrintFatal(fmt.Errorf("invalid -shutdown-timeout value %q", cfg.shutdownTimeout), nil)
+	}
+	idempotencyTTL, err := time.ParseDuration(cfg.idempotency.ttl)
+	if err != nil || idempotencyTTL <= 0 {
+		logger.PrintFatal(fmt.Errorf("invalid -idempotency-ttl value %q", cfg.idempotency.ttl), nil)
+	}
+	if cfg.tracing.sampleRatio < 0 || cfg.tracing.sampleRatio > 1 {
+		logger.PrintFatal(fmt.Errorf("invalid -otel-sample-ratio value %g, must be between 0 and 1", cfg.tracing.sampleRatio), nil)
+	}
+	if !validator.In(cfg.accessLog.format, accesslog.Formats...) {
+		logger.PrintFatal(fmt.Errorf("invalid -access-log-format value %q, must be common, combined or json", cfg.accessLog.format), nil)
+	}
+	if cfg.accessLog.maxSize < 1 || cfg.accessLog.maxBackups < 0 {
+		logger.PrintFatal(errors.New("invalid access log values, -access-log-max-size must be at least 1 and -access-log-max-backups not negative"), nil)
+	}
 
 	// An instance of the application struct
 	app := &application{
-		config: cfg,
-		logger: logger,
-		models: data.NewModels(db),
+		config:         cfg,
+		logger:         logger,
+		corsMaxAge:     corsMaxAge,
+		idempotencyTTL: idempotencyTTL,
+		stopping:       make(chan struct{}),
 	}
 
-	// An HTTP server
-	srv := http.Server{
-		Addr:         fmt.Sprintf(":%d", cfg.port),
-		Handler:      app.routes(),
-		IdleTimeout:  time.Minute,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 40 * time.Second,
+	// Open the access log, a file is rotated once it reaches the max size
+	switch cfg.accessLog.output {
+	case "off":
+	case "stdout":
+		app.accessLog = accesslog.New(os.Stdout, cfg.accessLog.format)
+	default:
+		file, err := accesslog.OpenRotatingFile(cfg.accessLog.output, int64(cfg.accessLog.maxSize)*1_048_576, cfg.accessLog.maxBackups)
+		if err != nil {
+			logger.PrintFatal(err, nil)
+		}
+		defer file.Close()
+		app.accessLog = accesslog.New(file, cfg.accessLog.format)
 	}
 
-	// Start the server
-	logger.Printf("Starting %s server on port %s", cfg.env, srv.Addr)
+	// The in-memory storage needs no database, which is handy for demos
+	var db *sql.DB
+	if cfg.storage == "memory" {
+		app.models = data.NewMemoryModels()
+		logger.PrintInfo("using in-memory storage, all the data will be lost when the server stops", nil)
+	} else {
+		// Create a database connection pool
+		db, err = openDB(cfg)
+		if err != nil {
+			logger.PrintFatal(err, nil)
+		}
+		logger.PrintInfo("database connecton establisted", nil)
+
+		// Run the embedded migrations, or only report their state
+		switch cfg.migrate {
+		case "up":
+			err = migrateUp(db, logger)
+			if err != nil {
+				db.Close()
+				logger.PrintFatal(err, nil)
+			}
+		case "status":
+			err = migrateStatus(db, logger)
+			db.Close()
+			if err != nil {
+				logger.PrintFatal(err, nil)
+			}
+			return
+		}
+
+		app.models = data.NewModels(db, queryTimeout)
+	}
 
-	err = srv.ListenAndServe()
-	logger.Fatal(err)
+	// Cache the movement lookups, the catalog changes rarely
+	// The writes made by the other replicas empty the cache through LISTEN/NOTIFY
+	if cfg.cache.enabled {
+		movementCache := data.NewMovementCache(cfg.cache.size, cacheTTL)
+		app.models = data.WithCache(app.models, movementCache)
+
+		if db != nil {
+			app.background(func() {
+				err := movementCache.Listen(cfg.db.dsn, app.stopping, func(err error) {
+					logger.PrintError(fmt.Errorf("movement cache listener: %w", err), nil)
+				})
+				if err != nil {
+					logger.PrintError(fmt.Errorf("movement cache listener: %w", err), nil)
+				}
+			})
+		}
+	}
+
+	// Record the traces of the requests and of every call to the models
+	tracerProvider, shutdownTracing, err := setupTracing(cfg)
+	if err != nil {
+		logger.PrintFatal(err, nil)
+	}
+	app.tracer = tracerProvider.Tracer(tracerName)
+	if cfg.tracing.exporter != "none" {
+		app.models = data.WithTracing(app.models, tracerProvider)
+	}
+
+	// Collect the metrics, including the stats of the connection pool if there is one
+	app.metrics = newMetrics(db)
+
+	// Rate limit the clients in memory and sweep the buckets they have stopped using
+	// The in-memory limiter is also the fallback of the postgres one
+	memoryLimiter := ratelimit.NewMemory()
+	app.limiter = memoryLimiter
+	idleTime := app.limiterIdleTime()
+	app.background(func() { memoryLimiter.SweepEvery(time.Minute, idleTime, app.stopping) })
+
+	// Share the limits between the replicas by keeping them in the database
+	if cfg.limiter.store == "postgres" {
+		pgLimiter := ratelimit.NewPostgres(db, memoryLimiter, limiterTimeout, log.New(logger, "", 0))
+		app.limiter = pgLimiter
+		app.background(func() { pgLimiter.SweepEvery(time.Minute, app.stopping) })
+	}
+
+	// Delete the expired idempotency keys along with the responses they hold
+	app.background(func() { app.sweepIdempotencyKeysEvery(time.Minute) })
+
+	// Start the server, this only returns once the server has been shut down
+	// The background loops have been waited for by then, unless the shutdown ran out of time
+	err = app.serve()
+
+	// Send the spans that are still buffered before exiting
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := shutdownTracing(ctx); err != nil {
+		logger.PrintError(fmt.Errorf("flushing the traces: %w", err), nil)
+	}
+	cancel()
+
+	// Close the connection pool even if the shutdown did not go cleanly
+	if db != nil {
+		logger.PrintInfo("closing the database connection pool", nil)
+		db.Close()
+	}
+
+	if err != nil {
+		logger.PrintFatal(err, nil)
+	}
 }
 
 // This function returns a SQLdb connection pool
@@ -121,3 +409,54 @@ func openDB(cfg config) (*sql.DB, error) {
 	// Return the connection pool
 	return db, nil
 }
+
+// This function applies the pending embedded migrations
+func migrateUp(db *sql.DB, logger *jsonlog.Logger) error {
+	migrator, err := migrate.New(db, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	// Give the other replicas time to finish if they are holding the migration lock
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	applied, err := migrator.Up(ctx)
+	for _, migration := range applied {
+		logger.PrintInfo("applied migration", map[string]string{
+			"version": fmt.Sprintf("%06d", migration.Version),
+			"name":    migration.Name,
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	logger.PrintInfo("database schema is up to date", nil)
+	return nil
+}
+
+// This function logs the state of every embedded migration
+func migrateStatus(db *sql.DB, logger *jsonlog.Logger) error {
+	migrator, err := migrate.New(db, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		logger.PrintInfo("migration status", map[string]string{
+			"version": fmt.Sprintf("%06d", status.Version),
+			"name":    status.Name,
+			"state":   status.State,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/arnab4477/Parkour_API/internal/validator"
+	"github.com/julienschmidt/httprouter"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// The namespace of all the metrics of the API
+const metricsNamespace = "parkour"
+
+// The route label of the requests that did not match any route
+const unmatchedRoute = "unmatched"
+
+// metrics struct holds the Prometheus collectors of the API
+type metrics struct {
+	registry *prometheus.Registry
+
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	inFlight prometheus.Gauge
+
+	logins        *prometheus.CounterVec
+	registrations prometheus.Counter
+	activations   prometheus.Counter
+}
+
+// This returns the metrics of the API registered in a registry of their own
+// The connection pool stats are only collected if there is a database
+func newMetrics(db *sql.DB) *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests served, by route, method and status.",
+		}, []string{"route", "method", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time taken to serve the HTTP requests, by route, method and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_requests_in_flight",
+			Help:      "Number of HTTP requests being served.",
+		}),
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "logins_total",
+			Help:      "Number of login attempts, by result.",
+		}, []string{"result"}),
+		registrations: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "registrations_total",
+			Help:      "Number of users registered.",
+		}),
+		activations: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "activations_total",
+			Help:      "Number of users activated.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		m.requests, m.duration, m.inFlight,
+		m.logins, m.registrations, m.activations,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	if db != nil {
+		m.registry.MustRegister(collectors.NewDBStatsCollector(db, metricsNamespace))
+	}
+
+	// Start the login results at 0 so that a rate can be taken before the first failure
+	m.logins.WithLabelValues("success")
+	m.logins.WithLabelValues("failure")
+
+	return m
+}
+
+// Method that returns the handler for the /metrics endpoint
+func (m *metrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Middleware that records the route pattern of a request, so that the metrics are not labeled
+// with every ID that shows up in the paths
+func (app *application) recordRoute(route string, next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		if info, ok := app.contextGetRequestInfo(r); ok {
+			info.route = route
+		}
+		next(w, r, ps)
+	}
+}
+
+// Middleware that counts the requests and how long they take, by route, method and status
+func (app *application) collectMetrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rw := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		app.metrics.inFlight.Inc()
+		defer app.metrics.inFlight.Dec()
+
+		next.ServeHTTP(rw, r)
+
+		route := unmatchedRoute
+		if info, ok := app.contextGetRequestInfo(r); ok && info.route != "" {
+			route = info.route
+		}
+
+		// Only the known methods are labeled, anybody can send a request with a made up one
+		method := r.Method
+		if !validator.In(method, http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+			http.MethodPatch, http.MethodDelete, http.MethodOptions) {
+			method = "other"
+		}
+
+		labels := prometheus.Labels{"route": route, "method": method, "status": strconv.Itoa(rw.status)}
+		app.metrics.requests.With(labels).Inc()
+		app.metrics.duration.With(labels).Observe(time.Since(start).Seconds())
+	})
+}
+
+// Method that returns the routes of the admin listener, which is kept apart from the API
+// so that the metrics are never exposed to the public
+func (app *application) adminRoutes() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", app.metrics.handler())
+	return mux
+}
@@ -2,14 +2,63 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"runtime/debug"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/arnab4477/Parkour_API/internal/data"
 	"github.com/arnab4477/Parkour_API/internal/validator"
 	"github.com/julienschmidt/httprouter"
 )
 
+// Middleware that recovers from a panic in a handler and sends a 500 JSON response
+// instead of net/http dropping the connection
+func (app *application) recoverPanic(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+
+			// http.ErrAbortHandler is how a handler asks net/http to abort the response on purpose
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+
+			properties := app.requestProperties(r, http.StatusInternalServerError)
+			properties["remote_addr"] = r.RemoteAddr
+			properties["stack"] = string(debug.Stack())
+			app.logger.PrintError(fmt.Errorf("panic: %v", p), properties)
+
+			// Close the connection after the response as the handler may have left it in a bad state
+			w.Header().Set("Connection", "close")
+			app.writeError(w, r, http.StatusInternalServerError, serverErrorMessage)
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Middleware that gives every request an ID for the logs and the X-Request-ID header
+// An ID sent by the client or a proxy in front of the API is kept so that the logs can be matched up
+func (app *application) requestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		w.Header().Set("X-Request-ID", id)
+		r = app.contextSetRequestInfo(r, &requestInfo{id: id, start: time.Now()})
+
+		next.ServeHTTP(w, r)
+	})
+}
+
 // Middleware to authenticate an user making the request
 func (app *application) authenticate(next httprouter.Handle) httprouter.Handle {
 	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
@@ -43,7 +92,7 @@ func (app *application) authenticate(next httprouter.Handle) httprouter.Handle {
 		}
 
 		// Get the user associated with the tokwn
-		user, err := app.models.Users.GetUserFromToken(data.ScopeAuthentication, token)
+		user, err := app.models.Users.GetUserFromToken(r.Context(), data.ScopeAuthentication, token)
 		if err != nil {
 			switch {
 			case errors.Is(err, data.ErrNotFound):
@@ -82,28 +131,72 @@ func (app *application) requireActivatedUser(next httprouter.Handle) httprouter.
 	}
 }
 
-// Middleware for enabling CORS and handle pre-flight request
-func (app *application) allowCORS(next httprouter.Handle) httprouter.Handle {
-	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+// The headers the browsers are allowed to read from the responses
+const corsExposedHeaders = "X-Request-ID, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, Retry-After, Content-Disposition, Idempotent-Replayed"
+
+// Middleware for enabling CORS for the trusted origins and handle pre-flight request
+// The origin of the request is echoed back so that credentialed requests work too
+func (app *application) enableCORS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Add("Vary", "Origin")
 		w.Header().Add("Vary", "Access-Control-Request-Method")
+		w.Header().Add("Vary", "Access-Control-Request-Headers")
 
 		origin := r.Header.Get("Origin")
-		if origin != "" {
-			// Enable CORS for all origins
-			w.Header().Set("Access-Control-Allow-Origin", "*")
+		origins := app.config.cors.trustedOrigins
+		trusted := origin != "" && origins.match(origin)
+
+		if trusted || (origin != "" && origins.any()) {
+			if trusted {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				// Browsers only send cookies and the Authorization header along if this is set,
+				// which must never be done for an origin that is only trusted through "*"
+				if app.config.cors.allowCredentials {
+					w.Header().Set("Access-Control-Allow-Credentials", "true")
+				}
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			}
+			w.Header().Set("Access-Control-Expose-Headers", corsExposedHeaders)
 
 			// Check if the request is a pre-flight request
 			// If it is, set the necessary headers and send a 200 OK status back
 			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
-				w.Header().Set("Access-Control-Allow-Methods", "OPTIONS, PUT, PATCH, DELETE")
-				w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+				w.Header().Set("Access-Control-Allow-Methods", "OPTIONS, GET, POST, PUT, PATCH, DELETE")
+				w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, X-Request-ID, Idempotency-Key, traceparent, tracestate")
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(app.corsMaxAge.Seconds())))
 
 				w.WriteHeader(http.StatusOK)
 				return
 			}
 		}
 
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Middleware that checks if the activated user has a specific permission
+func (app *application) requirePermission(code string, next httprouter.Handle) httprouter.Handle {
+	fn := func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		// Get the user information from the request context
+		user := app.contextGetUser(r)
+
+		// Get the permissions of the user
+		permissions, err := app.models.Permissions.GetAllForUser(r.Context(), user.ID)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		// Check if the user has the required permission
+		if !permissions.Include(code) {
+			app.notPermittedResponse(w, r)
+			return
+		}
+
 		next(w, r, ps)
 	}
+
+	// The user must be activated before the permissions are checked
+	return app.requireActivatedUser(fn)
 }
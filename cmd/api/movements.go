@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -43,6 +44,18 @@ func (app *application) getMovementsHandler(w http.ResponseWriter, r *http.Reque
 
 	params.SortSafeList = []string{"id", "name", "difficulty", "-name", "-difficulty"}
 
+	// Only the fields asked for are selected, such as fields=id,name,image
+	fields := app.readCsv(queries, "fields", []string{})
+	data.ValidateFields(v, fields, data.MovementFields)
+
+	// The related records to embed, such as include=prerequisites,creator
+	includes := parseIncludes(v, app.readCsv(queries, "include", []string{}))
+	v.Check(len(includes) > 0 && app.contextGetFormat(r) == formatCSV, "include", "can't be used with the csv format")
+
+	// The fields that the relations are loaded from are selected too
+	params.Filters.Fields = withFields(fields, includes.requiredFields())
+	params.FieldsSafeList = data.MovementFields
+
 	// Check if the query parameters for filtering data are valid
 	if data.ValidateFilters(v, params.Filters); !v.NoErrors() {
 		app.failedValidationError(w, r, v.Errors)
@@ -51,7 +64,7 @@ func (app *application) getMovementsHandler(w http.ResponseWriter, r *http.Reque
 
 	// Get the list of the movements from the database
 	movements, err := app.models.Movements.GetAllMovements(
-		params.Name, params.Difficulty, params.Skilltype,
+		r.Context(), params.Name, params.Difficulty, params.Skilltype,
 		params.Muscles, params.Equipments, params.Filters,
 	)
 
@@ -61,7 +74,15 @@ func (app *application) getMovementsHandler(w http.ResponseWriter, r *http.Reque
 	}
 
 	// Send all the data back as JSON
-	err = app.writeJSON(w, envelope{"movements": movements}, http.StatusOK, nil)
+	// Embed the related records in the movements
+	views := newMovementViews(movements, fields)
+	err = app.loadIncludes(r.Context(), views, includes)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeResponse(w, r, envelope{"movements": views}, http.StatusOK, nil)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -86,6 +107,7 @@ func (app *application) createMovementHandler(w http.ResponseWriter, r *http.Req
 		Difficulty    string   `json:"difficulty"`
 		Equipments    []string `json:"equipments"`
 		Prerequisites []string `json:"prerequisite"`
+		VariationOf   *int64   `json:"variation_of"`
 	}
 
 	// Decode the JSON request and send an appropriate response in case of an error
@@ -106,6 +128,8 @@ func (app *application) createMovementHandler(w http.ResponseWriter, r *http.Req
 		Difficulty:    input.Difficulty,
 		Equipments:    input.Equipments,
 		Prerequisites: input.Prerequisites,
+		VariationOf:   input.VariationOf,
+		CreatorID:     &app.contextGetUser(r).ID,
 	}
 
 	// Initiate a new Validator instance
@@ -113,14 +137,20 @@ func (app *application) createMovementHandler(w http.ResponseWriter, r *http.Req
 
 	// If there are no errors then proceed
 	// Else send error response back
-	if data.ValidateMovement(v, movement); !v.NoErrors() {
+	data.ValidateMovement(v, movement)
+	err = app.validateVariationOf(r.Context(), v, movement)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if !v.NoErrors() {
 		app.failedValidationError(w, r, v.Errors)
 		return
 	}
 
 	// Call the insert method on the movement model passing in the validated movement struct
 	// This will create a new record in the Movements table in the database
-	err = app.models.Movements.InsertOneMovement(movement)
+	err = app.models.Movements.InsertOneMovement(r.Context(), movement)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
@@ -131,7 +161,7 @@ func (app *application) createMovementHandler(w http.ResponseWriter, r *http.Req
 	header.Set("Location", fmt.Sprintf("v1/movements/%d", movement.ID))
 
 	// Send a response with the appropriate status code (201), the movement data and the header
-	err = app.writeJSON(w, envelope{"movement": movement}, http.StatusCreated, header)
+	err = app.writeResponse(w, r, envelope{"movement": movement}, http.StatusCreated, header)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -147,8 +177,21 @@ func (app *application) getOneMovementHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	// Only the fields asked for are sent, all of them if there are none
+	// and the related records of include are embedded in the movement
+	queries := r.URL.Query()
+	fields := app.readCsv(queries, "fields", []string{})
+	v := validator.NewValidator()
+	data.ValidateFields(v, fields, data.MovementFields)
+	includes := parseIncludes(v, app.readCsv(queries, "include", []string{}))
+	v.Check(len(includes) > 0 && app.contextGetFormat(r) == formatCSV, "include", "can't be used with the csv format")
+	if !v.NoErrors() {
+		app.failedValidationError(w, r, v.Errors)
+		return
+	}
+
 	// Fetch data for a specific movement
-	movement, err := app.models.Movements.GetOneMovement(id)
+	movement, err := app.models.Movements.GetOneMovement(r.Context(), id)
 	if err != nil {
 		if errors.Is(err, data.ErrNotFound) {
 			app.notFoundResponse(w, r)
@@ -160,7 +203,14 @@ func (app *application) getOneMovementHandler(w http.ResponseWriter, r *http.Req
 	}
 
 	// Send response with the movement data
-	err = app.writeJSON(w, envelope{"movement": movement}, http.StatusOK, nil)
+	view := &movementView{movement: movement, fields: fields}
+	err = app.loadIncludes(r.Context(), []*movementView{view}, includes)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeResponse(w, r, envelope{"movement": view}, http.StatusOK, nil)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -177,7 +227,7 @@ func (app *application) updateMovementHandler(w http.ResponseWriter, r *http.Req
 	}
 
 	// Fetch data for a specific movement
-	movement, err := app.models.Movements.GetOneMovement(id)
+	movement, err := app.models.Movements.GetOneMovement(r.Context(), id)
 	if err != nil {
 		if errors.Is(err, data.ErrNotFound) {
 			app.notFoundResponse(w, r)
@@ -201,6 +251,7 @@ func (app *application) updateMovementHandler(w http.ResponseWriter, r *http.Req
 		Difficulty    string   `json:"difficulty"`
 		Equipments    []string `json:"equipments"`
 		Prerequisites []string `json:"prerequisite"`
+		VariationOf   *int64   `json:"variation_of"`
 	}
 
 	// Decode the JSON request and send an appropriate response in case of an error
@@ -220,27 +271,34 @@ func (app *application) updateMovementHandler(w http.ResponseWriter, r *http.Req
 	movement.Difficulty = input.Difficulty
 	movement.Equipments = input.Equipments
 	movement.Prerequisites = input.Prerequisites
+	movement.VariationOf = input.VariationOf
 
 	// Initiate a new Validator instance
 	v := validator.NewValidator()
 
 	// If there are no errors then proceed
 	// Else send error response back
-	if data.ValidateMovement(v, movement); !v.NoErrors() {
+	data.ValidateMovement(v, movement)
+	err = app.validateVariationOf(r.Context(), v, movement)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if !v.NoErrors() {
 		app.failedValidationError(w, r, v.Errors)
 		return
 	}
 
 	// Call the UpdateMovement method on the movement model passing in the validated movement struct
 	// This will update an existing record a record in the Movements table in the database
-	err = app.models.Movements.UpdateOneMovement(movement)
+	err = app.models.Movements.UpdateOneMovement(r.Context(), movement)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
 	// Send response with the movement data
-	err = app.writeJSON(w, envelope{"movement": movement}, http.StatusOK, nil)
+	err = app.writeResponse(w, r, envelope{"movement": movement}, http.StatusOK, nil)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -257,7 +315,7 @@ func (app *application) deleteMovementHandler(w http.ResponseWriter, r *http.Req
 	}
 
 	//Delete the record from the database
-	err = app.models.Movements.DeleteOneMovement(id)
+	err = app.models.Movements.DeleteOneMovement(r.Context(), id)
 	if err != nil {
 		if errors.Is(err, data.ErrNotFound) {
 			app.notFoundResponse(w, r)
@@ -269,8 +327,53 @@ func (app *application) deleteMovementHandler(w http.ResponseWriter, r *http.Req
 	}
 
 	// Send response about the success of deletion
-	err = app.writeJSON(w, envelope{"movement": "movement successfully deleted"}, http.StatusOK, nil)
+	err = app.writeJSON(w, r, envelope{"movement": "movement successfully deleted"}, http.StatusOK, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// Handler method on the app instance for the GET /movements/changes endpount
+func (app *application) getMovementChangesHandler(w http.ResponseWriter, r *http.Request, _ps httprouter.Params) {
+
+	// Read the sync token from the query parameters
+	// An empty token returns every movement in the catalog as inserted
+	since, err := data.DecodeSyncToken(app.readStrings(r.URL.Query(), "since", ""))
+	if err != nil {
+		v := validator.NewValidator()
+		v.AddError("since", "must be a valid sync token")
+		app.failedValidationError(w, r, v.Errors)
+		return
+	}
+
+	// Get the ids of the movements that changed since the token
+	changes, err := app.models.Movements.GetMovementChanges(r.Context(), since)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// Send the changes and the next sync token back as JSON
+	err = app.writeJSON(w, r, envelope{"changes": changes}, http.StatusOK, nil)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
 }
+
+// Method on the app instance to check that a movement is a variation of another movement that exists
+func (app *application) validateVariationOf(ctx context.Context, v *validator.Validator, movement *data.Movement) error {
+	if movement.VariationOf == nil {
+		return nil
+	}
+	if *movement.VariationOf == movement.ID {
+		v.AddError("variation_of", "must not be the movement itself")
+		return nil
+	}
+
+	_, err := app.models.Movements.GetOneMovement(ctx, *movement.VariationOf)
+	if errors.Is(err, data.ErrNotFound) {
+		v.AddError("variation_of", "must be an existing movement")
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/arnab4477/Parkour_API/internal/catalog"
+	"github.com/arnab4477/Parkour_API/internal/data"
+	"github.com/julienschmidt/httprouter"
+	"github.com/vmihailenco/msgpack/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Constants for the formats the movements can be sent in
+const (
+	formatJSON    = "json"
+	formatCSV     = "csv"
+	formatXML     = "xml"
+	formatMsgpack = "msgpack"
+)
+
+// The media types of the formats, when the client accepts several of them equally
+// the first one wins
+var mediaTypes = []struct {
+	mediaType string
+	format    string
+}{
+	{"application/json", formatJSON},
+	{"text/csv", formatCSV},
+	{"application/xml", formatXML},
+	{"text/xml", formatXML},
+	{"application/msgpack", formatMsgpack},
+	{"application/x-msgpack", formatMsgpack},
+	{"application/vnd.msgpack", formatMsgpack},
+}
+
+// Function that returns the format for the Accept header of a request, or "" if the client accepts none of them
+// Every media type gets the quality of the most specific range that matches it,
+// so "text/*;q=0.5, text/csv" prefers CSV and "*/*, text/csv;q=0" rules it out
+func negotiateFormat(accept string) string {
+	if strings.TrimSpace(accept) == "" {
+		return formatJSON
+	}
+
+	type mediaRange struct {
+		mediaType string
+		q         float64
+	}
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		if mediaType == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range params[1:] {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(name, "q") {
+				parsed, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					parsed = 0
+				}
+				q = parsed
+			}
+		}
+		ranges = append(ranges, mediaRange{mediaType, q})
+	}
+
+	best, bestQ := "", 0.0
+	for _, candidate := range mediaTypes {
+		mainType := strings.Split(candidate.mediaType, "/")[0]
+
+		// Find the quality given by the most specific range, -1 if no range matches
+		q, specificity := 0.0, -1
+		for _, mr := range ranges {
+			s := -1
+			switch mr.mediaType {
+			case candidate.mediaType:
+				s = 2
+			case mainType + "/*":
+				s = 1
+			case "*/*":
+				s = 0
+			}
+			if s > specificity {
+				q, specificity = mr.q, s
+			}
+		}
+
+		if q > bestQ {
+			best, bestQ = candidate.format, q
+		}
+	}
+	return best
+}
+
+// Function that returns the content type of a format
+func formatContentType(format string) string {
+	switch format {
+	case formatCSV:
+		return "text/csv; charset=utf-8"
+	case formatXML:
+		return "application/xml; charset=utf-8"
+	case formatMsgpack:
+		return "application/msgpack"
+	default:
+		return "application/json"
+	}
+}
+
+// Middleware that picks the format of the response from the format query parameter or the Accept header
+// It sends a 406 before the handler runs if the client accepts none of the formats
+func (app *application) negotiate(next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		w.Header().Add("Vary", "Accept")
+
+		// The query parameter wins over the header, it is easier to set from a browser or a spreadsheet
+		format := r.URL.Query().Get("format")
+		switch format {
+		case formatJSON, formatCSV, formatXML, formatMsgpack:
+		case "":
+			format = negotiateFormat(r.Header.Get("Accept"))
+		default:
+			format = ""
+		}
+
+		if format == "" {
+			app.notAcceptableResponse(w, r)
+			return
+		}
+
+		r = app.contextSetFormat(r, format)
+		next(w, r, ps)
+	}
+}
+
+// Method on the app instance to write the movements of data in the format picked by negotiate
+// Only the movement and movements values of data can be sent as CSV or XML,
+// anything else is sent as JSON
+func (app *application) writeResponse(w http.ResponseWriter, r *http.Request, data envelope, status int, header http.Header) error {
+	format := app.contextGetFormat(r)
+	if format == formatJSON {
+		return app.writeJSON(w, r, data, status, header)
+	}
+
+	views, list, ok := envelopeMovements(data)
+	if !ok && format != formatMsgpack {
+		return app.writeJSON(w, r, data, status, header)
+	}
+
+	_, span := app.tracer.Start(r.Context(), "writeResponse", trace.WithAttributes(attribute.String("format", format)))
+	defer span.End()
+
+	// Encode the whole body first so that an error can still be sent as JSON
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case formatCSV:
+		err = encodeCSV(&buf, views)
+	case formatXML:
+		err = encodeXML(&buf, views, list)
+	case formatMsgpack:
+		encoder := msgpack.NewEncoder(&buf)
+		// Use the same field names as the JSON responses
+		encoder.SetCustomStructTag("json")
+		err = encoder.Encode(data)
+	}
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	span.SetAttributes(attribute.Int("bytes", buf.Len()))
+
+	for key, value := range header {
+		w.Header()[key] = value
+	}
+
+	w.Header().Set("Content-Type", formatContentType(format))
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+	return nil
+}
+
+// Function that returns the views of the movements in data and if they are a list
+func envelopeMovements(env envelope) ([]*movementView, bool, bool) {
+	switch value := env["movements"].(type) {
+	case []*data.Movement:
+		return newMovementViews(value, nil), true, true
+	case []*movementView:
+		return value, true, true
+	}
+
+	switch value := env["movement"].(type) {
+	case *data.Movement:
+		return newMovementViews([]*data.Movement{value}, nil), false, true
+	case *movementView:
+		return []*movementView{value}, false, true
+	}
+	return nil, false, false
+}
+
+// Function that writes the picked fields of the movements as CSV, the lists are separated with pipes
+// The whole movements are sent in the same columns as the catalog export, so that they can be imported back
+// CSV has no room for the embedded records, the handlers refuse to include any with it
+func encodeCSV(buf *bytes.Buffer, views []*movementView) error {
+	var fields []string
+	if len(views) > 0 {
+		// The fields are the same for every movement of a list
+		fields = views[0].fields
+	}
+
+	if len(fields) == 0 {
+		movements := make([]*data.Movement, len(views))
+		for i, view := range views {
+			movements[i] = view.movement
+		}
+		return catalog.Export(buf, catalog.FormatCSV, movements)
+	}
+
+	writer := csv.NewWriter(buf)
+	err := writer.Write(fields)
+	if err != nil {
+		return err
+	}
+
+	record := make([]string, len(fields))
+	for _, view := range views {
+		for i, field := range fields {
+			switch value := view.movement.Field(field).(type) {
+			case []string:
+				record[i] = strings.Join(value, "|")
+			case *int64:
+				record[i] = ""
+				if value != nil {
+					record[i] = strconv.FormatInt(*value, 10)
+				}
+			default:
+				record[i] = fmt.Sprint(value)
+			}
+		}
+
+		err := writer.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// movementXML struct holds a movement as it is sent in XML
+// The fields are pointers so that the ones that were not picked are left out
+type movementXML struct {
+	XMLName       xml.Name  `xml:"movement"`
+	ID            *int64    `xml:"id,omitempty"`
+	Name          *string   `xml:"name,omitempty"`
+	Description   *string   `xml:"description,omitempty"`
+	Image         *string   `xml:"image,omitempty"`
+	Tutorials     *[]string `xml:"tutorials>tutorial,omitempty"`
+	Skilltype     *[]string `xml:"skilltype>type,omitempty"`
+	Muscles       *[]string `xml:"muscles>muscle,omitempty"`
+	Difficulty    *string   `xml:"difficulty,omitempty"`
+	Equipments    *[]string `xml:"equipments>equipment,omitempty"`
+	Prerequisites *[]string `xml:"prerequisite>movement,omitempty"`
+	Version       *int32    `xml:"version,omitempty"`
+	CreatorID     *int64    `xml:"creator_id,omitempty"`
+	VariationOf   *int64    `xml:"variation_of,omitempty"`
+
+	// The embedded records
+	PrerequisiteMovements *[]movementXML `xml:"prerequisites>movement,omitempty"`
+	Variations            *[]movementXML `xml:"variations>movement,omitempty"`
+	Creator               *creatorView   `xml:"creator,omitempty"`
+}
+
+// Function that returns the XML of a view and of the records embedded in it
+func newMovementXML(view *movementView) movementXML {
+	var record movementXML
+	movement := view.movement
+	for _, field := range view.viewFields() {
+		switch field {
+		case "id":
+			record.ID = &movement.ID
+		case "name":
+			record.Name = &movement.Name
+		case "description":
+			record.Description = &movement.Description
+		case "image":
+			record.Image = &movement.Image
+		case "tutorials":
+			record.Tutorials = &movement.Tutorials
+		case "skilltype":
+			record.Skilltype = &movement.Skilltype
+		case "muscles":
+			record.Muscles = &movement.Muscles
+		case "difficulty":
+			record.Difficulty = &movement.Difficulty
+		case "equipments":
+			record.Equipments = &movement.Equipments
+		case "prerequisite":
+			record.Prerequisites = &movement.Prerequisites
+		case "version":
+			record.Version = &movement.Version
+		case "creator_id":
+			record.CreatorID = movement.CreatorID
+		case "variation_of":
+			record.VariationOf = movement.VariationOf
+		}
+	}
+
+	related := func(relation string) *[]movementXML {
+		views, ok := view.included[relation].([]*movementView)
+		if !ok {
+			return nil
+		}
+		records := make([]movementXML, len(views))
+		for i, v := range views {
+			records[i] = newMovementXML(v)
+		}
+		return &records
+	}
+	record.PrerequisiteMovements = related(includePrerequisites)
+	record.Variations = related(includeVariations)
+	record.Creator, _ = view.included[includeCreator].(*creatorView)
+
+	return record
+}
+
+// Function that writes the movements as XML, a list is wrapped in a movements element
+func encodeXML(buf *bytes.Buffer, views []*movementView, list bool) error {
+	records := make([]movementXML, len(views))
+	for i, view := range views {
+		records[i] = newMovementXML(view)
+	}
+
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(buf)
+	encoder.Indent("", "\t")
+
+	var err error
+	if list {
+		err = encoder.Encode(struct {
+			XMLName   xml.Name      `xml:"movements"`
+			Movements []movementXML `xml:"movement"`
+		}{Movements: records})
+	} else {
+		err = encoder.Encode(records[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	buf.WriteByte('\n')
+	return nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"embed"
+	"net/http"
+	"path"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// The OpenAPI document of every route, it is written by hand so it must be updated with the routes
+// openapi_test.go fails if a route is registered without being in it
+//
+//go:embed docs/openapi.json
+var openAPISpec []byte
+
+// The Swagger UI page for the document
+//
+//go:embed docs/swagger.html
+var swaggerPage []byte
+
+// The files of swagger-ui-dist that the page loads, they are served by the API itself
+// so that the docs work offline and no third-party script runs on the origin of the API
+// See docs/swagger-ui/README.md for the version and how to update them
+//
+//go:embed docs/swagger-ui/*.js docs/swagger-ui/*.css
+var swaggerAssets embed.FS
+
+// endpoint holds the method and the path of a registered route
+type endpoint struct {
+	method string
+	path   string
+}
+
+// Handler method on the app instance for the GET /openapi.json endpoint
+func (app *application) openAPIHandler(w http.ResponseWriter, r *http.Request, _ps httprouter.Params) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPISpec)
+}
+
+// Handler method on the app instance for the GET /docs endpoint
+func (app *application) docsHandler(w http.ResponseWriter, r *http.Request, _ps httprouter.Params) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(swaggerPage)
+}
+
+// Handler method on the app instance for the GET /docs/swagger-ui/:file endpoint
+func (app *application) docsAssetHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	name := ps.ByName("file")
+
+	asset, err := swaggerAssets.ReadFile("docs/swagger-ui/" + name)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	switch path.Ext(name) {
+	case ".js":
+		w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+	case ".css":
+		w.Header().Set("Content-Type", "text/css; charset=utf-8")
+	}
+	// The files only change with a new release of the API
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.Write(asset)
+}
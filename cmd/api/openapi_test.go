@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/arnab4477/Parkour_API/internal/data"
+	"github.com/arnab4477/Parkour_API/internal/jsonlog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Test that every route registered in routes() is described in the OpenAPI document
+func TestOpenAPICoversRoutes(t *testing.T) {
+	var spec struct {
+		OpenAPI string                                `json:"openapi"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	err := json.Unmarshal(openAPISpec, &spec)
+	if err != nil {
+		t.Fatalf("docs/openapi.json is not valid JSON: %v", err)
+	}
+	if spec.OpenAPI != "3.1.0" {
+		t.Errorf("got openapi %q, want 3.1.0", spec.OpenAPI)
+	}
+
+	app := &application{
+		logger:  jsonlog.New(os.Stdout, jsonlog.LevelError, jsonlog.FormatText),
+		tracer:  trace.NewNoopTracerProvider().Tracer("test"),
+		metrics: newMetrics(nil),
+		models:  data.NewMemoryModels(),
+	}
+	app.routes()
+
+	if len(app.endpoints) == 0 {
+		t.Fatal("routes() registered no endpoints")
+	}
+
+	// The changes are dispatched from the :id route rather than registered, see movementIDHandler
+	endpoints := append(app.endpoints, endpoint{"GET", "/v1/movements/changes"})
+
+	for _, e := range endpoints {
+		// httprouter writes the parameters as :id, OpenAPI as {id}
+		segments := strings.Split(e.path, "/")
+		for i, segment := range segments {
+			if strings.HasPrefix(segment, ":") {
+				segments[i] = "{" + segment[1:] + "}"
+			}
+		}
+		path := strings.Join(segments, "/")
+
+		if _, ok := spec.Paths[path][strings.ToLower(e.method)]; !ok {
+			t.Errorf("%s %s is registered but missing from docs/openapi.json", e.method, path)
+		}
+	}
+}
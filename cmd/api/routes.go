@@ -3,11 +3,12 @@ package main
 import (
 	"net/http"
 
+	"github.com/arnab4477/Parkour_API/internal/data"
 	"github.com/julienschmidt/httprouter"
 )
 
 // Route method on the app struct to handle the routing and adding handlers
-func (app *application) routes() *httprouter.Router {
+func (app *application) routes() http.Handler {
 	// Declare a new httprouter router instance
 	router := httprouter.New()
 
@@ -16,19 +17,77 @@ func (app *application) routes() *httprouter.Router {
 	router.NotFound = http.HandlerFunc(app.notFoundResponse)
 	router.MethodNotAllowed = http.HandlerFunc(app.methodNotAllowedResponse)
 
+	// Every route is registered with handle, which records its pattern for the metrics
+	// and keeps it in the endpoints, which the OpenAPI document is checked against
+	app.endpoints = nil
+	handle := func(method, path string, handler httprouter.Handle) {
+		app.endpoints = append(app.endpoints, endpoint{method, path})
+		router.Handle(method, path, app.recordRoute(path, handler))
+	}
+
+	// Every route goes through the rate limiter, the name is the limit it uses
+	// Routes without a -limiter-route of their own share the global limit
+	// The limiter comes after authenticate so that authenticated users are limited per user
+	// The movements can be sent in any of the formats negotiate picks, the rest is always JSON
+
 	// Register the handlers for the /movements/ endpoints
-	router.GET("/v1/healthcheck", app.healthcheckHandler)
-	router.GET("/v1/movements", app.allowCORS(app.getMovementsHandler))
-	router.GET("/v1/movements/:id", app.allowCORS(app.getOneMovementHandler))
+	handle(http.MethodGet, "/v1/healthcheck", app.healthcheckHandler)
+	handle(http.MethodGet, "/v1/openapi.json", app.openAPIHandler)
+	handle(http.MethodGet, "/v1/docs", app.docsHandler)
+	handle(http.MethodGet, "/v1/docs/swagger-ui/:file", app.docsAssetHandler)
+	handle(http.MethodGet, "/v1/movements", app.rateLimit(globalLimit, app.negotiate(app.getMovementsHandler)))
+	handle(http.MethodGet, "/v1/movements/:id", app.rateLimit(globalLimit, app.movementIDHandler))
+
+	handle(http.MethodPost, "/v1/movements", app.authenticate(app.rateLimit(globalLimit, app.requireActivatedUser(app.negotiate(app.idempotent("/v1/movements", app.createMovementHandler))))))
+	handle(http.MethodPut, "/v1/movements/:id", app.authenticate(app.rateLimit(globalLimit, app.requireActivatedUser(app.negotiate(app.updateMovementHandler)))))
+	handle(http.MethodDelete, "/v1/movements/:id", app.authenticate(app.rateLimit(globalLimit, app.requireActivatedUser(app.deleteMovementHandler))))
 
-	router.POST("/v1/movements", app.allowCORS(app.authenticate(app.requireActivatedUser(app.createMovementHandler))))
-	router.PUT("/v1/movements/:id", app.allowCORS(app.authenticate(app.requireActivatedUser(app.updateMovementHandler))))
-	router.DELETE("/v1/movements/:id", app.allowCORS(app.authenticate(app.requireActivatedUser(app.deleteMovementHandler))))
+	// Register the handlers for the /admin/ endpoints
+	handle(http.MethodGet, "/v1/admin/movements/export", app.authenticate(app.rateLimit("export", app.requirePermission(data.PermissionMovementsAdmin, app.exportMovementsHandler))))
+	handle(http.MethodPost, "/v1/admin/movements/import", app.authenticate(app.rateLimit("import", app.requirePermission(data.PermissionMovementsAdmin, app.importMovementsHandler))))
+
+	// Register the handler for the /graphql/ endpoint, the bearer token is optional like on the movement lookups
+	handle(http.MethodPost, "/v1/graphql", app.authenticate(app.rateLimit(globalLimit, app.graphqlHandler())))
 
 	// Register the handlers for the /users/ endpoints
-	router.POST("/v1/users", app.allowCORS(app.registerUserHandler))
-	router.POST("/v1/users/activate", app.allowCORS(app.activateUserHandler))
-	router.POST("/v1/users/login", app.allowCORS(app.loginHandler))
+	handle(http.MethodPost, "/v1/users", app.rateLimit("register", app.idempotent("/v1/users", app.registerUserHandler)))
+	handle(http.MethodPost, "/v1/users/activate", app.rateLimit("activate", app.activateUserHandler))
+	handle(http.MethodPost, "/v1/users/login", app.rateLimit("login", app.loginHandler))
+
+	// CORS is enabled for every route, including the errors sent by the middleware on the routes
+	// Recover from the panics in any of the handlers and middleware above
+	handler := app.recoverPanic(app.enableCORS(router))
+
+	// Compress the responses, including the 500s from panics
+	if app.config.compression.enabled {
+		handler = app.compress(handler)
+	}
+
+	// The access log and the metrics go around the recovery so that the 500s from panics are counted too
+	if app.accessLog != nil {
+		handler = app.logAccess(handler)
+	}
+	handler = app.collectMetrics(handler)
+
+	// The trace covers everything else, it needs the request ID to name its span after the route
+	if app.config.tracing.exporter != "none" {
+		handler = app.traceRequests(handler)
+	}
+
+	// The request ID comes first so that everything after it can be logged with it
+	return app.requestID(handler)
+}
+
+// httprouter does not allow a static segment such as /v1/movements/changes to share its position
+// with the :id wildcard, so the GET requests for both are dispatched from this handler
+func (app *application) movementIDHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if ps.ByName("id") == "changes" {
+		if info, ok := app.contextGetRequestInfo(r); ok {
+			info.route = "/v1/movements/changes"
+		}
+		app.getMovementChangesHandler(w, r, ps)
+		return
+	}
 
-	return router
+	app.negotiate(app.getOneMovementHandler)(w, r, ps)
 }
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// Method that starts the HTTP server and blocks until it has been shut down
+// On SIGINT or SIGTERM the server stops accepting connections, lets the in-flight requests
+// finish and waits for the background tasks, all within the shutdown grace period
+func (app *application) serve() error {
+	gracePeriod, err := time.ParseDuration(app.config.shutdownTimeout)
+	if err != nil || gracePeriod <= 0 {
+		return fmt.Errorf("invalid -shutdown-timeout value %q", app.config.shutdownTimeout)
+	}
+
+	// An HTTP server
+	srv := &http.Server{
+		Addr:         fmt.Sprintf(":%d", app.config.port),
+		Handler:      app.routes(),
+		IdleTimeout:  time.Minute,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 40 * time.Second,
+		ErrorLog:     log.New(app.logger, "", 0),
+	}
+
+	// The admin listener serves the metrics apart from the API
+	var adminSrv *http.Server
+	if app.config.adminAddr != "" {
+		adminSrv = &http.Server{
+			Addr:         app.config.adminAddr,
+			Handler:      app.adminRoutes(),
+			IdleTimeout:  time.Minute,
+			ReadTimeout:  10 * time.Second,
+			WriteTimeout: 30 * time.Second,
+			ErrorLog:     log.New(app.logger, "", 0),
+		}
+
+		go func() {
+			app.logger.PrintInfo("starting admin server", map[string]string{
+				"addr": adminSrv.Addr,
+			})
+
+			// The API keeps running without its metrics if the admin listener cannot start
+			err := adminSrv.ListenAndServe()
+			if !errors.Is(err, http.ErrServerClosed) {
+				app.logger.PrintError(fmt.Errorf("admin server: %w", err), nil)
+			}
+		}()
+	}
+
+	// The gRPC services are served on a port of their own
+	// Unlike the admin listener the API does not start without them, the clients rely on them
+	var grpcSrv *grpc.Server
+	if app.config.grpcPort != 0 {
+		listener, err := net.Listen("tcp", fmt.Sprintf(":%d", app.config.grpcPort))
+		if err != nil {
+			app.stopServing(gracePeriod)
+			return fmt.Errorf("grpc server: %w", err)
+		}
+		grpcSrv = app.grpcServer()
+
+		go func() {
+			app.logger.PrintInfo("starting grpc server", map[string]string{
+				"addr": listener.Addr().String(),
+			})
+
+			err := grpcSrv.Serve(listener)
+			if err != nil {
+				app.logger.PrintError(fmt.Errorf("grpc server: %w", err), nil)
+			}
+		}()
+	}
+
+	// Channel to receive the outcome of the shutdown
+	shutdownError := make(chan error)
+
+	go func() {
+		// Wait for an interrupt or a termination signal
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+		s := <-quit
+
+		app.logger.PrintInfo("caught signal, shutting down the server", map[string]string{
+			"signal": s.String(),
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), gracePeriod)
+		defer cancel()
+
+		// The metrics are not needed anymore once the server is shutting down
+		if adminSrv != nil {
+			adminSrv.Close()
+		}
+
+		// The gRPC server drains its calls alongside the HTTP server
+		grpcStopped := make(chan struct{})
+		go func() {
+			if grpcSrv != nil {
+				grpcSrv.GracefulStop()
+			}
+			close(grpcStopped)
+		}()
+
+		// Stop accepting new connections and wait for the in-flight requests to finish
+		err := srv.Shutdown(ctx)
+		if err != nil {
+			shutdownError <- fmt.Errorf("in-flight requests did not finish in time: %w", err)
+			return
+		}
+
+		// Without a gRPC server grpcStopped is closed straight away, but it could still lose
+		// the select to ctx.Done at the deadline
+		if grpcSrv != nil {
+			select {
+			case <-grpcStopped:
+			case <-ctx.Done():
+				grpcSrv.Stop()
+				shutdownError <- errors.New("in-flight grpc calls did not finish in time")
+				return
+			}
+		}
+		app.logger.PrintInfo("all in-flight requests have finished", nil)
+
+		// Wait for the background tasks, such as sending emails, with the rest of the grace period
+		// The loops that run for as long as the server, such as the sweeps, are told to return first
+		app.logger.PrintInfo("waiting for the background tasks to finish", nil)
+		if !app.waitBackground(ctx) {
+			shutdownError <- errors.New("background tasks did not finish in time")
+			return
+		}
+		app.logger.PrintInfo("all background tasks have finished", nil)
+		shutdownError <- nil
+	}()
+
+	// Start the server
+	app.logger.PrintInfo("starting server", map[string]string{
+		"addr": srv.Addr,
+		"env":  app.config.env,
+	})
+
+	// ListenAndServe returns http.ErrServerClosed straight away once Shutdown is called,
+	// any other error means that the server could not start
+	err = srv.ListenAndServe()
+	if !errors.Is(err, http.ErrServerClosed) {
+		app.stopServing(gracePeriod)
+		return err
+	}
+
+	err = <-shutdownError
+	if err != nil {
+		return err
+	}
+
+	app.logger.PrintInfo("stopped server", map[string]string{
+		"addr": srv.Addr,
+	})
+	return nil
+}
+
+// Method that stops the background loops when the server could not start, so that they are
+// not using the connection pool when main closes it
+// There is no shutdown to share the grace period with, so they get the whole of it
+func (app *application) stopServing(gracePeriod time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), gracePeriod)
+	defer cancel()
+
+	if !app.waitBackground(ctx) {
+		app.logger.PrintError(errors.New("background tasks did not finish in time"), nil)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/semconv/v1.17.0/httpconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The name of the service in the traces
+const serviceName = "parkour-api"
+
+// The name of the tracer that the HTTP spans are recorded with
+const tracerName = "github.com/arnab4477/Parkour_API/cmd/api"
+
+// This function sets up the tracer provider for the exporter in the config
+// With no exporter the traces are not recorded at all, the returned function flushes
+// the spans that have not been exported yet and must be called before exiting
+func setupTracing(cfg config) (trace.TracerProvider, func(context.Context) error, error) {
+	// Take the W3C trace context and baggage from the incoming requests
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if cfg.tracing.exporter == "none" {
+		return trace.NewNoopTracerProvider(), func(context.Context) error { return nil }, nil
+	}
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.tracing.exporter {
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case "otlp":
+		// Without an endpoint the exporter uses OTEL_EXPORTER_OTLP_ENDPOINT, or localhost:4318
+		options := []otlptracehttp.Option{}
+		if cfg.tracing.endpoint != "" {
+			options = append(options, otlptracehttp.WithEndpoint(cfg.tracing.endpoint))
+		}
+		if cfg.tracing.insecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(context.Background(), options...)
+	default:
+		err = fmt.Errorf("invalid -otel-exporter value %q, must be none, stdout or otlp", cfg.tracing.exporter)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(version),
+		semconv.DeploymentEnvironment(cfg.env),
+	))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Keep the decision of the caller if there is one, so that a trace is never half recorded
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.tracing.sampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+
+	return tp, tp.Shutdown, nil
+}
+
+// Middleware that records a span for every request, continuing the trace of the caller if it sent one
+func (app *application) traceRequests(next http.Handler) http.Handler {
+	propagator := otel.GetTextMapPropagator()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+		// The span is named after the route once it is known, the path could have an ID in it
+		ctx, span := app.tracer.Start(ctx, "HTTP "+r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(httpconv.ServerRequest("", r)...),
+		)
+		defer span.End()
+
+		rw := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		r = r.WithContext(ctx)
+
+		next.ServeHTTP(rw, r)
+
+		if info, ok := app.contextGetRequestInfo(r); ok {
+			span.SetAttributes(attribute.String("request_id", info.id))
+			if info.route != "" {
+				span.SetName(r.Method + " " + info.route)
+				span.SetAttributes(semconv.HTTPRoute(info.route))
+			}
+			if info.user != nil && !info.user.IsAnonymous() {
+				span.SetAttributes(attribute.Int64("user_id", info.user.ID))
+			}
+		}
+
+		span.SetAttributes(semconv.HTTPStatusCode(rw.status))
+		span.SetStatus(httpconv.ServerStatus(rw.status))
+	})
+}
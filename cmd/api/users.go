@@ -47,8 +47,18 @@ func (app *application) registerUserHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// Insert the user data into the database
-	err = app.models.Users.InsertOneUser(user)
+	// Insert the user and generate their activation token in a single transaction
+	// so that a failure can never leave an user who can not be activated
+	var token *data.Token
+	err = app.models.Transaction(r.Context(), func(tx data.Models) error {
+		err := tx.Users.InsertOneUser(r.Context(), user)
+		if err != nil {
+			return err
+		}
+
+		token, err = tx.Tokens.NewToken(r.Context(), user.ID, 2*24*time.Hour, data.ScopeActivation)
+		return err
+	})
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrDuplicateEmail):
@@ -59,13 +69,7 @@ func (app *application) registerUserHandler(w http.ResponseWriter, r *http.Reque
 		}
 		return
 	}
-
-	// Generate an activation token for the user
-	token, err := app.models.Tokens.NewToken(user.ID, 2*24*time.Hour, data.ScopeActivation)
-	if err != nil {
-		app.serverErrorResponse(w, r, err)
-		return
-	}
+	app.metrics.registrations.Inc()
 
 	responseData := map[string]interface{}{
 		"activationToken": token.PlainText,
@@ -74,7 +78,7 @@ func (app *application) registerUserHandler(w http.ResponseWriter, r *http.Reque
 
 	// If there has been no error, send the user as JSON response to the client
 	// along with the appropriate status code
-	err = app.writeJSON(w, envelope{"data": responseData}, http.StatusCreated, nil)
+	err = app.writeJSON(w, r, envelope{"data": responseData}, http.StatusCreated, nil)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -101,7 +105,7 @@ func (app *application) activateUserHandler(w http.ResponseWriter, r *http.Reque
 	}
 
 	// Retrieve the user from the token
-	user, err := app.models.Users.GetUserFromToken(data.ScopeActivation, input.TokenPlaintext)
+	user, err := app.models.Users.GetUserFromToken(r.Context(), data.ScopeActivation, input.TokenPlaintext)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrNotFound):
@@ -112,9 +116,20 @@ func (app *application) activateUserHandler(w http.ResponseWriter, r *http.Reque
 		}
 		return
 	}
-	// Activate and update the user record
-	user.Activated = true
-	err = app.models.Users.UpdateOneUser(user)
+	// Activate the user and delete all their activation tokens in a single transaction
+	// The version is reset on every attempt because a retried transaction starts over
+	version := user.Version
+	err = app.models.Transaction(r.Context(), func(tx data.Models) error {
+		user.Activated = true
+		user.Version = version
+
+		err := tx.Users.UpdateOneUser(r.Context(), user)
+		if err != nil {
+			return err
+		}
+
+		return tx.Tokens.DeleteTokens(r.Context(), user.ID, data.ScopeActivation)
+	})
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrEditConflict):
@@ -124,14 +139,10 @@ func (app *application) activateUserHandler(w http.ResponseWriter, r *http.Reque
 		}
 		return
 	}
-	// Delete all activation tokens for the user
-	err = app.models.Tokens.DeleteTokens(user.ID, data.ScopeActivation)
-	if err != nil {
-		app.serverErrorResponse(w, r, err)
-		return
-	}
+	app.metrics.activations.Inc()
+
 	// Send the updated user details to the client in a JSON response.
-	err = app.writeJSON(w, envelope{"user": user}, http.StatusOK, nil)
+	err = app.writeJSON(w, r, envelope{"user": user}, http.StatusOK, nil)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -162,10 +173,11 @@ func (app *application) loginHandler(w http.ResponseWriter, r *http.Request, _ps
 	}
 
 	// Lookup the user record based on the email
-	user, err := app.models.Users.GetOneUserByEmail(input.Email)
+	user, err := app.models.Users.GetOneUserByEmail(r.Context(), input.Email)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrNotFound):
+			app.metrics.logins.WithLabelValues("failure").Inc()
 			app.invalidCredentialsResponse(w, r)
 		default:
 			app.serverErrorResponse(w, r, err)
@@ -180,24 +192,27 @@ func (app *application) loginHandler(w http.ResponseWriter, r *http.Request, _ps
 		return
 	}
 	if !match {
+		app.metrics.logins.WithLabelValues("failure").Inc()
 		app.invalidCredentialsResponse(w, r)
 		return
 	}
 
 	// Create the token with 30 days as expiry
-	token, err := app.models.Tokens.NewToken(user.ID, (24*30)*time.Hour, data.ScopeAuthentication)
+	token, err := app.models.Tokens.NewToken(r.Context(), user.ID, (24*30)*time.Hour, data.ScopeAuthentication)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
+	app.metrics.logins.WithLabelValues("success").Inc()
+
 	responseData := map[string]interface{}{
 		"authenticationToken": token,
 		"user":                user,
 	}
 
 	// Send the token as response
-	err = app.writeJSON(w, envelope{"data": responseData}, http.StatusCreated, nil)
+	err = app.writeJSON(w, r, envelope{"data": responseData}, http.StatusCreated, nil)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/arnab4477/Parkour_API/internal/data"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// movementView holds a movement as it is sent, with only the fields asked for with the
+// fields query parameter and the related records embedded with the include one
+type movementView struct {
+	movement *data.Movement
+	fields   []string // All the fields if it is nil
+
+	// The embedded records, in the order of includeRelations
+	// A value is []*movementView for the prerequisites and variations, and *creatorView for the creator
+	included map[string]interface{}
+}
+
+// creatorView holds the user who added a movement, without anything private such as the email
+type creatorView struct {
+	ID       int64  `json:"id" xml:"id"`
+	Username string `json:"username" xml:"username"`
+}
+
+// Method that returns the fields of the view, all of them if none were picked
+func (mv *movementView) viewFields() []string {
+	if len(mv.fields) == 0 {
+		return data.MovementFields
+	}
+	return mv.fields
+}
+
+// Method that embeds the records of a relation in the view
+func (mv *movementView) include(relation string, value interface{}) {
+	if mv.included == nil {
+		mv.included = make(map[string]interface{})
+	}
+	mv.included[relation] = value
+}
+
+// Method that encodes the picked fields and the embedded records of the movement as a JSON object
+func (mv *movementView) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+
+	write := func(key string, value interface{}) error {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(`"` + key + `":`)
+		buf.Write(encoded)
+		return nil
+	}
+
+	for _, field := range mv.viewFields() {
+		err := write(field, mv.movement.Field(field))
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, relation := range includeRelations {
+		if value, ok := mv.included[relation]; ok {
+			err := write(relation, value)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Method that encodes the picked fields and the embedded records of the movement as a MessagePack map
+func (mv *movementView) EncodeMsgpack(encoder *msgpack.Encoder) error {
+	fields := mv.viewFields()
+	err := encoder.EncodeMapLen(len(fields) + len(mv.included))
+	if err != nil {
+		return err
+	}
+
+	write := func(key string, value interface{}) error {
+		err := encoder.EncodeString(key)
+		if err != nil {
+			return err
+		}
+		return encoder.Encode(value)
+	}
+
+	for _, field := range fields {
+		err = write(field, mv.movement.Field(field))
+		if err != nil {
+			return err
+		}
+	}
+	for _, relation := range includeRelations {
+		if value, ok := mv.included[relation]; ok {
+			err = write(relation, value)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Function that returns the views of the movements with the given fields
+func newMovementViews(movements []*data.Movement, fields []string) []*movementView {
+	views := make([]*movementView, len(movements))
+	for i, movement := range movements {
+		views[i] = &movementView{movement: movement, fields: fields}
+	}
+	return views
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/arnab4477/Parkour_API/internal/data"
+
+	_ "github.com/lib/pq"
+)
+
+// The usage message printed for -h and unknown commands
+const usage = `Usage: calictl [flags] <command> [command flags] [arguments]
+
+Commands:
+  users create -username=NAME -email=EMAIL -password=PASSWORD [-activated]
+  users activate -email=EMAIL
+  permissions grant -email=EMAIL CODE...
+  tokens revoke -email=EMAIL [-scope=all|activation|authentication]
+  tokens purge
+  idempotency purge
+  movements export [-format=json|ndjson|csv] [-o=FILE]
+  movements import [-format=json|ndjson|csv] [-dry-run] FILE
+  migrate up
+  migrate down [-steps=1] [-all]
+  migrate status
+  stats
+
+Flags:
+`
+
+// The application struct
+type application struct {
+	db     *sql.DB
+	models data.Models
+	stdout io.Writer
+}
+
+// A command is a function that runs against the application with the remaining arguments
+type command func(app *application, args []string) error
+
+// All the commands keyed by their name and the name of their action
+var commands = map[string]map[string]command{
+	"users": {
+		"create":   createUserCommand,
+		"activate": activateUserCommand,
+	},
+	"permissions": {
+		"grant": grantPermissionsCommand,
+	},
+	"tokens": {
+		"revoke": revokeTokensCommand,
+		"purge":  purgeTokensCommand,
+	},
+	"idempotency": {
+		"purge": purgeIdempotencyKeysCommand,
+	},
+	"movements": {
+		"export": exportCommand,
+		"import": importCommand,
+	},
+	"migrate": {
+		"up":     migrateUpCommand,
+		"down":   migrateDownCommand,
+		"status": migrateStatusCommand,
+	},
+	"stats": {
+		"": statsCommand,
+	},
+}
+
+func main() {
+	// Use the same database flag as cmd/api so the same DSN works for both
+	var dsn string
+	flag.StringVar(&dsn, "db-dsn", os.Getenv("PARKOUR_DB_DSN"), "PostgreSQL DSN")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	// Find the command to run from the arguments
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	actions, exists := commands[args[0]]
+	if !exists {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	action, args := "", args[1:]
+	if _, single := actions[""]; !single {
+		if len(args) == 0 {
+			flag.Usage()
+			os.Exit(2)
+		}
+		action, args = args[0], args[1:]
+	}
+
+	run, exists := actions[action]
+	if !exists {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Create a database connection pool
+	db, err := openDB(dsn)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "calictl:", err)
+		os.Exit(1)
+	}
+
+	app := &application{
+		db:     db,
+		models: data.NewModels(db, 0), // Commands run until they finish, without a query timeout
+		stdout: os.Stdout,
+	}
+
+	err = run(app, args)
+	db.Close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "calictl:", err)
+		os.Exit(1)
+	}
+}
+
+// This function returns a SQLdb connection pool
+func openDB(dsn string) (*sql.DB, error) {
+	if dsn == "" {
+		return nil, fmt.Errorf("the -db-dsn flag or the PARKOUR_DB_DSN variable must be set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// A command only needs a single connection at a time
+	db.SetMaxOpenConns(2)
+
+	// If the connecton is not established within 5 seconds, this will return an error
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err = db.PingContext(ctx)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// Method that prints a value as indented JSON
+func (app *application) printJSON(value interface{}) error {
+	encoder := json.NewEncoder(app.stdout)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(value)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math"
+
+	"github.com/arnab4477/Parkour_API/internal/migrate"
+	"github.com/arnab4477/Parkour_API/migrations"
+)
+
+// Command that applies all the pending migrations
+func migrateUpCommand(app *application, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("migrate up does not take any arguments")
+	}
+
+	migrator, err := migrate.New(app.db, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	applied, err := migrator.Up(context.Background())
+	for _, migration := range applied {
+		fmt.Fprintf(app.stdout, "applied %06d_%s\n", migration.Version, migration.Name)
+	}
+	return err
+}
+
+// Command that reverts the latest applied migrations, or all of them with -all
+func migrateDownCommand(app *application, args []string) error {
+	fs := flag.NewFlagSet("migrate down", flag.ContinueOnError)
+	steps := fs.Int("steps", 1, "Number of migrations to revert")
+	all := fs.Bool("all", false, "Revert every applied migration")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *steps < 1 {
+		return fmt.Errorf("steps must be greater than zero")
+	}
+	if *all {
+		*steps = math.MaxInt
+	}
+
+	migrator, err := migrate.New(app.db, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	reverted, err := migrator.Down(context.Background(), *steps)
+	for _, migration := range reverted {
+		fmt.Fprintf(app.stdout, "reverted %06d_%s\n", migration.Version, migration.Name)
+	}
+	return err
+}
+
+// Command that prints the state of every migration
+func migrateStatusCommand(app *application, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("migrate status does not take any arguments")
+	}
+
+	migrator, err := migrate.New(app.db, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	statuses, err := migrator.Status(context.Background())
+	if err != nil {
+		return err
+	}
+
+	return app.printJSON(statuses)
+}
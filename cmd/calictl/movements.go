@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/arnab4477/Parkour_API/internal/catalog"
+	"github.com/arnab4477/Parkour_API/internal/validator"
+)
+
+// Command that writes the whole movement catalog to a file or to stdout
+func exportCommand(app *application, args []string) error {
+	fs := flag.NewFlagSet("movements export", flag.ContinueOnError)
+	format := fs.String("format", catalog.FormatJSON, "File format (json | ndjson | csv)")
+	output := fs.String("o", "", "Output file, stdout if empty")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if !validator.In(*format, catalog.Formats...) {
+		return fmt.Errorf("unsupported format %q", *format)
+	}
+
+	movements, err := app.models.Movements.ExportMovements(context.Background())
+	if err != nil {
+		return err
+	}
+
+	w := app.stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	return catalog.Export(w, *format, movements)
+}
+
+// Command that imports movements from a file, or from stdin if the file is "-"
+func importCommand(app *application, args []string) error {
+	fs := flag.NewFlagSet("movements import", flag.ContinueOnError)
+	format := fs.String("format", catalog.FormatJSON, "File format (json | ndjson | csv)")
+	dryRun := fs.Bool("dry-run", false, "Only validate the file, do not write anything")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("import needs exactly one file argument")
+	}
+	if !validator.In(*format, catalog.Formats...) {
+		return fmt.Errorf("unsupported format %q", *format)
+	}
+
+	var r io.Reader = os.Stdin
+	if fs.Arg(0) != "-" {
+		file, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	rows, err := catalog.Import(r, *format)
+	if err != nil {
+		return err
+	}
+
+	report, err := app.models.Movements.ImportMovements(context.Background(), rows, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	// Print the report so that the failed rows can be fixed
+	err = app.printJSON(report)
+	if err != nil {
+		return err
+	}
+
+	if report.Failed > 0 {
+		return fmt.Errorf("%d rows failed validation, nothing was imported", report.Failed)
+	}
+	return nil
+}
+
+// Command that prints the summary of the movement catalog
+func statsCommand(app *application, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("stats does not take any arguments")
+	}
+
+	stats, err := app.models.Movements.GetCatalogStats(context.Background())
+	if err != nil {
+		return err
+	}
+
+	return app.printJSON(stats)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/arnab4477/Parkour_API/internal/data"
+	"github.com/arnab4477/Parkour_API/internal/validator"
+)
+
+// Command that deletes the tokens of an user, logging them out of every client
+func revokeTokensCommand(app *application, args []string) error {
+	fs := flag.NewFlagSet("tokens revoke", flag.ContinueOnError)
+	email := fs.String("email", "", "Email address of the user")
+	scope := fs.String("scope", "all", "Scope of the tokens (all | activation | authentication)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if !validator.In(*scope, "all", data.ScopeActivation, data.ScopeAuthentication) {
+		return fmt.Errorf("unsupported scope %q", *scope)
+	}
+
+	user, err := app.getUserByEmail(*email)
+	if err != nil {
+		return err
+	}
+
+	if *scope == "all" {
+		err = app.models.Tokens.DeleteAllTokensForUser(context.Background(), user.ID)
+	} else {
+		err = app.models.Tokens.DeleteTokens(context.Background(), user.ID, *scope)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(app.stdout, "revoked %s tokens of %s\n", *scope, user.Email)
+	return nil
+}
+
+// Command that deletes every expired token
+func purgeTokensCommand(app *application, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("purge does not take any arguments")
+	}
+
+	deleted, err := app.models.Tokens.DeleteExpiredTokens(context.Background())
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(app.stdout, "purged %d expired tokens\n", deleted)
+	return nil
+}
+
+// Command that deletes every expired Idempotency-Key along with its stored response
+// The API also sweeps them every minute, this is for when it is not running
+func purgeIdempotencyKeysCommand(app *application, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("purge does not take any arguments")
+	}
+
+	deleted, err := app.models.Idempotency.DeleteExpiredIdempotencyKeys(context.Background())
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(app.stdout, "purged %d expired idempotency keys\n", deleted)
+	return nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/arnab4477/Parkour_API/internal/data"
+	"github.com/arnab4477/Parkour_API/internal/validator"
+)
+
+// Command that creates a new user, optionally already activated
+func createUserCommand(app *application, args []string) error {
+	fs := flag.NewFlagSet("users create", flag.ContinueOnError)
+	username := fs.String("username", "", "Username of the user")
+	email := fs.String("email", "", "Email address of the user")
+	password := fs.String("password", "", "Plain text password of the user")
+	activated := fs.Bool("activated", false, "Create the user already activated")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	user := &data.User{
+		Username:  *username,
+		Email:     *email,
+		Activated: *activated,
+	}
+
+	// Hash the user's plain text password
+	err := user.Password.SetHash(*password)
+	if err != nil {
+		return err
+	}
+
+	// Validate the user with the same rules as the API
+	v := validator.NewValidator()
+	if data.ValidateUser(v, user); !v.NoErrors() {
+		return validationError(v)
+	}
+
+	err = app.models.Users.InsertOneUser(context.Background(), user)
+	if err != nil {
+		if errors.Is(err, data.ErrDuplicateEmail) {
+			return fmt.Errorf("a user with the email %s already exists", user.Email)
+		}
+		return err
+	}
+
+	return app.printJSON(user)
+}
+
+// Command that activates an user and deletes their activation tokens
+func activateUserCommand(app *application, args []string) error {
+	fs := flag.NewFlagSet("users activate", flag.ContinueOnError)
+	email := fs.String("email", "", "Email address of the user")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	user, err := app.getUserByEmail(*email)
+	if err != nil {
+		return err
+	}
+
+	// Activate the user and delete their activation tokens in a single transaction
+	// The user is reset on every attempt because a retried transaction starts over
+	activated, version := user.Activated, user.Version
+	err = app.models.Transaction(context.Background(), func(tx data.Models) error {
+		user.Activated, user.Version = activated, version
+		if !activated {
+			user.Activated = true
+			err := tx.Users.UpdateOneUser(context.Background(), user)
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Tokens.DeleteTokens(context.Background(), user.ID, data.ScopeActivation)
+	})
+	if err != nil {
+		return err
+	}
+
+	return app.printJSON(user)
+}
+
+// Command that grants one or more permissions to an user
+func grantPermissionsCommand(app *application, args []string) error {
+	fs := flag.NewFlagSet("permissions grant", flag.ContinueOnError)
+	email := fs.String("email", "", "Email address of the user")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		return fmt.Errorf("grant needs at least one permission code, such as %s", data.PermissionMovementsAdmin)
+	}
+
+	user, err := app.getUserByEmail(*email)
+	if err != nil {
+		return err
+	}
+
+	err = app.models.Permissions.AddForUser(context.Background(), user.ID, fs.Args()...)
+	if err != nil {
+		return err
+	}
+
+	// Print the permissions the user ends up with, so a mistyped code is easy to notice
+	permissions, err := app.models.Permissions.GetAllForUser(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	return app.printJSON(map[string]interface{}{
+		"user":        user,
+		"permissions": permissions,
+	})
+}
+
+// Method that finds an user by their email address
+func (app *application) getUserByEmail(email string) (*data.User, error) {
+	v := validator.NewValidator()
+	if data.ValidateEmail(v, email); !v.NoErrors() {
+		return nil, validationError(v)
+	}
+
+	user, err := app.models.Users.GetOneUserByEmail(context.Background(), email)
+	if err != nil {
+		if errors.Is(err, data.ErrNotFound) {
+			return nil, fmt.Errorf("no user with the email %s", email)
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// Function that turns the errors of a validator into a single error
+func validationError(v *validator.Validator) error {
+	message := "invalid input:"
+	for key, value := range v.Errors {
+		message += fmt.Sprintf(" %s %s;", key, value)
+	}
+	return errors.New(message)
+}
@@ -0,0 +1,130 @@
+package accesslog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// The formats an access log can be written in
+const (
+	FormatCommon   = "common"   // NCSA Common Log Format
+	FormatCombined = "combined" // Common Log Format with the referer and the user agent
+	FormatJSON     = "json"
+)
+
+// All the supported formats
+var Formats = []string{FormatCommon, FormatCombined, FormatJSON}
+
+// Entry struct holds everything recorded about a single request
+type Entry struct {
+	Time      time.Time
+	RequestID string
+	ClientIP  string
+	UserID    string // Empty for anonymous users
+	Method    string
+	URI       string
+	Proto     string
+	Status    int
+	Bytes     int64
+	Duration  time.Duration
+	Referer   string
+	UserAgent string
+}
+
+// Logger struct writes the access log entries in a single format
+type Logger struct {
+	out    io.Writer
+	format string
+	mu     sync.Mutex
+}
+
+// This returns a new access logger that writes to out
+func New(out io.Writer, format string) *Logger {
+	return &Logger{out: out, format: format}
+}
+
+// Method that writes an entry as a single line
+func (l *Logger) Log(e Entry) error {
+	line := e.Format(l.format)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, err := l.out.Write(line)
+	return err
+}
+
+// Method that returns the entry as a line in the given format
+func (e Entry) Format(format string) []byte {
+	switch format {
+	case FormatCommon:
+		return []byte(e.common() + "\n")
+	case FormatCombined:
+		return []byte(fmt.Sprintf("%s %s %s\n", e.common(), strconv.Quote(dash(e.Referer)), strconv.Quote(dash(e.UserAgent))))
+	default:
+		line, err := json.Marshal(struct {
+			Time       string  `json:"time"`
+			RequestID  string  `json:"request_id,omitempty"`
+			ClientIP   string  `json:"client_ip"`
+			UserID     string  `json:"user_id,omitempty"`
+			Method     string  `json:"method"`
+			URI        string  `json:"uri"`
+			Proto      string  `json:"proto"`
+			Status     int     `json:"status"`
+			Bytes      int64   `json:"bytes"`
+			DurationMs float64 `json:"duration_ms"`
+			Referer    string  `json:"referer,omitempty"`
+			UserAgent  string  `json:"user_agent,omitempty"`
+		}{
+			Time:       e.Time.UTC().Format(time.RFC3339Nano),
+			RequestID:  e.RequestID,
+			ClientIP:   e.ClientIP,
+			UserID:     e.UserID,
+			Method:     e.Method,
+			URI:        e.URI,
+			Proto:      e.Proto,
+			Status:     e.Status,
+			Bytes:      e.Bytes,
+			DurationMs: float64(e.Duration.Microseconds()) / 1000,
+			Referer:    e.Referer,
+			UserAgent:  e.UserAgent,
+		})
+		if err != nil {
+			return []byte(fmt.Sprintf("{\"error\":%q}\n", err.Error()))
+		}
+		return append(line, '\n')
+	}
+}
+
+// Method that returns the entry in the Common Log Format
+// host ident authuser [date] "request line" status bytes
+func (e Entry) common() string {
+	return fmt.Sprintf("%s - %s [%s] \"%s %s %s\" %d %s",
+		dash(e.ClientIP),
+		dash(e.UserID),
+		e.Time.Format("02/Jan/2006:15:04:05 -0700"),
+		e.Method, e.URI, e.Proto,
+		e.Status,
+		dash(bytesField(e.Bytes)),
+	)
+}
+
+// Function that returns the number of bytes, the common format uses "-" for an empty body
+func bytesField(n int64) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+// Function that returns "-" for an empty field, as is the custom in the common format
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
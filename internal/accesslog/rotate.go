@@ -0,0 +1,121 @@
+package accesslog
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// RotatingFile is a file that is rotated once it would grow over MaxBytes
+// The rotated files are kept as path.1, path.2 and so on, with path.1 the most recent,
+// and only the last MaxBackups of them are kept
+type RotatingFile struct {
+	path       string
+	maxBytes   int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// This opens the file at path for appending, creating it if needed
+func OpenRotatingFile(path string, maxBytes int64, maxBackups int) (*RotatingFile, error) {
+	rf := &RotatingFile{
+		path:       path,
+		maxBytes:   maxBytes,
+		maxBackups: maxBackups,
+	}
+
+	err := rf.open()
+	if err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+// Method that writes p to the file, rotating it first if p does not fit anymore
+func (rf *RotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	// A file with nothing in it is never rotated, even if p alone is too big
+	// If the rotation fails p still goes to the file that is open, which keeps growing,
+	// and the error is returned so that it gets logged
+	var rotateErr error
+	if rf.size > 0 && rf.size+int64(len(p)) > rf.maxBytes {
+		rotateErr = rf.rotate()
+	}
+
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	if err == nil && rotateErr != nil {
+		err = fmt.Errorf("rotating %s: %w", rf.path, rotateErr)
+	}
+	return n, err
+}
+
+// Method that closes the file
+func (rf *RotatingFile) Close() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	return rf.file.Close()
+}
+
+// Method that opens the file and finds how big it already is
+func (rf *RotatingFile) open() error {
+	file, err := os.OpenFile(rf.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	rf.file = file
+	rf.size = info.Size()
+	return nil
+}
+
+// Method that moves the current file to path.1, shifting the older backups along
+// Whatever fails, the file at path is open again afterwards, unless it can't be opened at all
+func (rf *RotatingFile) rotate() error {
+	err := rf.file.Close()
+	if err == nil {
+		err = rf.shift()
+	}
+
+	openErr := rf.open()
+	if err != nil {
+		return err
+	}
+	return openErr
+}
+
+// Method that moves the file at path out of the way, dropping the oldest backup
+func (rf *RotatingFile) shift() error {
+	var err error
+
+	// Drop the oldest backup and shift the others, path.1 becomes path.2 and so on
+	if rf.maxBackups > 0 {
+		os.Remove(rf.backup(rf.maxBackups))
+		for i := rf.maxBackups - 1; i >= 1; i-- {
+			os.Rename(rf.backup(i), rf.backup(i+1))
+		}
+		err = os.Rename(rf.path, rf.backup(1))
+	} else {
+		err = os.Remove(rf.path)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Method that returns the path of the n-th backup
+func (rf *RotatingFile) backup(n int) string {
+	return fmt.Sprintf("%s.%d", rf.path, n)
+}
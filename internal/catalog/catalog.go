@@ -0,0 +1,300 @@
+package catalog
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/arnab4477/Parkour_API/internal/data"
+)
+
+// Constants for the supported file formats
+const (
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+)
+
+// All the supported file formats
+var Formats = []string{FormatJSON, FormatNDJSON, FormatCSV}
+
+// The separator used for the list fields in CSV files
+// A pipe is used because the tutorial links can contain commas
+const listSeparator = "|"
+
+// The columns of a CSV file, in the order they are exported
+var csvHeader = []string{
+	"id", "slug", "name", "description", "image", "tutorials", "skilltype",
+	"muscles", "difficulty", "equipments", "prerequisite", "version",
+}
+
+// Record struct holds a movement as it appears in an export file
+// The id and version are only informative and are ignored by imports
+type Record struct {
+	ID            int64    `json:"id,omitempty"`
+	Slug          string   `json:"slug"`
+	Name          string   `json:"name"`
+	Description   string   `json:"description"`
+	Image         string   `json:"image"`
+	Tutorials     []string `json:"tutorials"`
+	Skilltype     []string `json:"skilltype"`
+	Muscles       []string `json:"muscles"`
+	Difficulty    string   `json:"difficulty"`
+	Equipments    []string `json:"equipments"`
+	Prerequisites []string `json:"prerequisite"`
+	Version       int32    `json:"version,omitempty"`
+}
+
+// Function that returns the content type of a file format
+func ContentType(format string) string {
+	switch format {
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	default:
+		return "application/json"
+	}
+}
+
+// Function that returns the file format for a content type, or "" if it is not supported
+func FormatFromContentType(contentType string) string {
+	mediaType := strings.TrimSpace(strings.Split(contentType, ";")[0])
+	switch strings.ToLower(mediaType) {
+	case "application/json":
+		return FormatJSON
+	case "application/x-ndjson", "application/ndjson":
+		return FormatNDJSON
+	case "text/csv":
+		return FormatCSV
+	default:
+		return ""
+	}
+}
+
+// Function that creates a record from a movement
+func newRecord(movement *data.Movement) Record {
+	return Record{
+		ID:            movement.ID,
+		Slug:          data.Slugify(movement.Name),
+		Name:          movement.Name,
+		Description:   movement.Description,
+		Image:         movement.Image,
+		Tutorials:     movement.Tutorials,
+		Skilltype:     movement.Skilltype,
+		Muscles:       movement.Muscles,
+		Difficulty:    movement.Difficulty,
+		Equipments:    movement.Equipments,
+		Prerequisites: movement.Prerequisites,
+		Version:       movement.Version,
+	}
+}
+
+// Method that converts a record read from a file into an import row
+func (r Record) importRow(row int) data.ImportRow {
+	return data.ImportRow{
+		Row:  row,
+		Slug: r.Slug,
+		Movement: &data.Movement{
+			Name:          r.Name,
+			Description:   r.Description,
+			Image:         r.Image,
+			Tutorials:     r.Tutorials,
+			Skilltype:     r.Skilltype,
+			Muscles:       r.Muscles,
+			Difficulty:    r.Difficulty,
+			Equipments:    r.Equipments,
+			Prerequisites: r.Prerequisites,
+		},
+	}
+}
+
+// Function that writes the movements to w in the given format
+func Export(w io.Writer, format string, movements []*data.Movement) error {
+	switch format {
+	case FormatJSON:
+		records := make([]Record, len(movements))
+		for i, movement := range movements {
+			records[i] = newRecord(movement)
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "\t")
+		return encoder.Encode(records)
+
+	case FormatNDJSON:
+		// The encoder writes a new line after every value
+		encoder := json.NewEncoder(w)
+		for _, movement := range movements {
+			err := encoder.Encode(newRecord(movement))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		err := writer.Write(csvHeader)
+		if err != nil {
+			return err
+		}
+
+		for _, movement := range movements {
+			record := newRecord(movement)
+			err := writer.Write([]string{
+				strconv.FormatInt(record.ID, 10),
+				record.Slug,
+				record.Name,
+				record.Description,
+				record.Image,
+				strings.Join(record.Tutorials, listSeparator),
+				strings.Join(record.Skilltype, listSeparator),
+				strings.Join(record.Muscles, listSeparator),
+				record.Difficulty,
+				strings.Join(record.Equipments, listSeparator),
+				strings.Join(record.Prerequisites, listSeparator),
+				strconv.FormatInt(int64(record.Version), 10),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		writer.Flush()
+		return writer.Error()
+
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// Function that reads the rows of an import file in the given format
+// Rows are numbered from 1, not counting the header of CSV files
+func Import(r io.Reader, format string) ([]data.ImportRow, error) {
+	switch format {
+	case FormatJSON:
+		var records []Record
+		err := json.NewDecoder(r).Decode(&records)
+		if err != nil {
+			return nil, fmt.Errorf("body contains badly formed JSON: %w", err)
+		}
+
+		rows := make([]data.ImportRow, len(records))
+		for i, record := range records {
+			rows[i] = record.importRow(i + 1)
+		}
+		return rows, nil
+
+	case FormatNDJSON:
+		rows := []data.ImportRow{}
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 1_048_576)
+
+		// Blank lines are skipped but still counted so that the row numbers match the file
+		line := 0
+		for scanner.Scan() {
+			line++
+			if strings.TrimSpace(scanner.Text()) == "" {
+				continue
+			}
+
+			var record Record
+			err := json.Unmarshal(scanner.Bytes(), &record)
+			if err != nil {
+				return nil, fmt.Errorf("row %d contains badly formed JSON: %w", line, err)
+			}
+			rows = append(rows, record.importRow(line))
+		}
+
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return rows, nil
+
+	case FormatCSV:
+		return importCSV(r)
+
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// Function that reads the rows of a CSV import file
+// The columns are matched by the header so they can be in any order
+func importCSV(r io.Reader) ([]data.ImportRow, error) {
+	reader := csv.NewReader(r)
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("body must not be empty")
+		}
+		return nil, err
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		known := false
+		for _, column := range csvHeader {
+			if name == column {
+				known = true
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("header contains unknown column %q", name)
+		}
+		columns[name] = i
+	}
+
+	if _, exists := columns["name"]; !exists {
+		return nil, errors.New(`header must contain the "name" column`)
+	}
+
+	// Functions to read a single value or a list from a CSV record
+	value := func(fields []string, column string) string {
+		if i, exists := columns[column]; exists {
+			return fields[i]
+		}
+		return ""
+	}
+	list := func(fields []string, column string) []string {
+		if v := value(fields, column); v != "" {
+			return strings.Split(v, listSeparator)
+		}
+		return nil
+	}
+
+	rows := []data.ImportRow{}
+	for row := 1; ; row++ {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("row %d is badly formed: %w", row, err)
+		}
+
+		record := Record{
+			Slug:          value(fields, "slug"),
+			Name:          value(fields, "name"),
+			Description:   value(fields, "description"),
+			Image:         value(fields, "image"),
+			Tutorials:     list(fields, "tutorials"),
+			Skilltype:     list(fields, "skilltype"),
+			Muscles:       list(fields, "muscles"),
+			Difficulty:    value(fields, "difficulty"),
+			Equipments:    list(fields, "equipments"),
+			Prerequisites: list(fields, "prerequisite"),
+		}
+		rows = append(rows, record.importRow(row))
+	}
+
+	return rows, nil
+}
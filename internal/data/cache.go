@@ -0,0 +1,254 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arnab4477/Parkour_API/internal/cache"
+	"github.com/lib/pq"
+)
+
+// The channel the movements_changed trigger notifies when the movements table changes
+const MovementsChangedChannel = "movements_changed"
+
+// MovementCache holds the movements and the lists of movements that have been read recently
+// Any write to the movements empties it, as a single change can affect every cached list
+type MovementCache struct {
+	lru *cache.LRU[string, []*Movement]
+
+	// The generation is bumped on every invalidation, so that a read which started before
+	// a write can't put the old result back in the cache once the write is done
+	mu         sync.Mutex
+	generation uint64
+}
+
+// This returns a new cache of at most size entries that are kept for ttl
+func NewMovementCache(size int, ttl time.Duration) *MovementCache {
+	return &MovementCache{lru: cache.New[string, []*Movement](size, ttl)}
+}
+
+// Method that empties the cache
+func (c *MovementCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.lru.Purge()
+}
+
+// Method that returns the current generation of the cache
+func (c *MovementCache) currentGeneration() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
+
+// Method that caches the movements of key, unless the cache was invalidated since generation
+func (c *MovementCache) set(key string, generation uint64, movements []*Movement) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation == c.generation {
+		c.lru.Set(key, cloneMovements(movements))
+	}
+}
+
+// Method that returns the cached movements of key
+func (c *MovementCache) get(key string) ([]*Movement, bool) {
+	movements, found := c.lru.Get(key)
+	if !found {
+		return nil, false
+	}
+	return cloneMovements(movements), true
+}
+
+// Method that listens for the notifications of the movements_changed trigger and empties
+// the cache on each of them, so that a write on another replica is seen here too
+// The cache is emptied on every reconnect as well, since notifications can be missed meanwhile
+// It returns once stop is closed
+func (c *MovementCache) Listen(dsn string, stop <-chan struct{}, onError func(error)) error {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			onError(err)
+		}
+	})
+	defer listener.Close()
+
+	err := listener.Listen(MovementsChangedChannel)
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-listener.Notify:
+			// A nil notification means that the connection was re-established
+			c.Invalidate()
+		case <-time.After(90 * time.Second):
+			// Check the connection every now and then, so that a dead one is noticed
+			go listener.Ping()
+		case <-stop:
+			return nil
+		}
+	}
+}
+
+// This returns the models with the movement lookups going through c first
+// Every write to the movements through these models empties c,
+// for the transactions that happens once they are over
+func WithCache(m Models, c *MovementCache) Models {
+	cached := m
+	cached.Movements = cachedMovementStore{MovementStore: m.Movements, cache: c}
+
+	runInTx := m.runInTx
+	cached.runInTx = func(ctx context.Context, fn func(tx Models) error) error {
+		// The movements are not cached inside a transaction, it could still roll back
+		wrote := false
+		err := runInTx(ctx, func(tx Models) error {
+			tx.Movements = txMovementStore{MovementStore: tx.Movements, wrote: &wrote}
+			return fn(tx)
+		})
+
+		// Also invalidate after a failed transaction, the commit could have gone through anyway
+		if wrote {
+			c.Invalidate()
+		}
+		return err
+	}
+
+	return cached
+}
+
+// cachedMovementStore serves the movement lookups from the cache when it can
+type cachedMovementStore struct {
+	MovementStore
+	cache *MovementCache
+}
+
+func (s cachedMovementStore) GetAllMovements(ctx context.Context, name string, difficulty string, skilltype []string, muscles []string, equipments []string, filters Filters) ([]*Movement, error) {
+	key := listCacheKey(name, difficulty, skilltype, muscles, equipments, filters)
+	if movements, found := s.cache.get(key); found {
+		return movements, nil
+	}
+
+	generation := s.cache.currentGeneration()
+	movements, err := s.MovementStore.GetAllMovements(ctx, name, difficulty, skilltype, muscles, equipments, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.set(key, generation, movements)
+	return movements, nil
+}
+
+func (s cachedMovementStore) GetOneMovement(ctx context.Context, id int64) (*Movement, error) {
+	key := fmt.Sprintf("movement:%d", id)
+	if movements, found := s.cache.get(key); found {
+		return movements[0], nil
+	}
+
+	// A movement that is not found is not cached, those lookups are rare
+	generation := s.cache.currentGeneration()
+	movement, err := s.MovementStore.GetOneMovement(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.set(key, generation, []*Movement{movement})
+	return movement, nil
+}
+
+func (s cachedMovementStore) InsertOneMovement(ctx context.Context, movement *Movement) error {
+	defer s.cache.Invalidate()
+	return s.MovementStore.InsertOneMovement(ctx, movement)
+}
+
+func (s cachedMovementStore) UpdateOneMovement(ctx context.Context, movement *Movement) error {
+	defer s.cache.Invalidate()
+	return s.MovementStore.UpdateOneMovement(ctx, movement)
+}
+
+func (s cachedMovementStore) DeleteOneMovement(ctx context.Context, id int64) error {
+	defer s.cache.Invalidate()
+	return s.MovementStore.DeleteOneMovement(ctx, id)
+}
+
+func (s cachedMovementStore) ImportMovements(ctx context.Context, rows []ImportRow, dryRun bool) (*ImportReport, error) {
+	if !dryRun {
+		defer s.cache.Invalidate()
+	}
+	return s.MovementStore.ImportMovements(ctx, rows, dryRun)
+}
+
+// txMovementStore notes if the movements have been written to in a transaction
+type txMovementStore struct {
+	MovementStore
+	wrote *bool
+}
+
+func (s txMovementStore) InsertOneMovement(ctx context.Context, movement *Movement) error {
+	*s.wrote = true
+	return s.MovementStore.InsertOneMovement(ctx, movement)
+}
+
+func (s txMovementStore) UpdateOneMovement(ctx context.Context, movement *Movement) error {
+	*s.wrote = true
+	return s.MovementStore.UpdateOneMovement(ctx, movement)
+}
+
+func (s txMovementStore) DeleteOneMovement(ctx context.Context, id int64) error {
+	*s.wrote = true
+	return s.MovementStore.DeleteOneMovement(ctx, id)
+}
+
+func (s txMovementStore) ImportMovements(ctx context.Context, rows []ImportRow, dryRun bool) (*ImportReport, error) {
+	if !dryRun {
+		*s.wrote = true
+	}
+	return s.MovementStore.ImportMovements(ctx, rows, dryRun)
+}
+
+// Function that returns the cache key of a list query
+// Filters that give the same result get the same key: the name search and the difficulty
+// ignore the case and the order of the array filters and of the fields doesn't matter
+func listCacheKey(name string, difficulty string, skilltype []string, muscles []string, equipments []string, filters Filters) string {
+	return fmt.Sprintf("movements:name=%q,difficulty=%q,skilltype=%s,muscles=%s,equipments=%s,sort=%s,page=%d,page_size=%d,fields=%s",
+		strings.ToLower(strings.TrimSpace(name)),
+		strings.ToLower(difficulty),
+		normalizeList(skilltype),
+		normalizeList(muscles),
+		normalizeList(equipments),
+		filters.Sort,
+		filters.Page,
+		filters.PageSize,
+		normalizeList(filters.Fields),
+	)
+}
+
+// Function that returns the values of a filter sorted and without duplicates, quoted as one string
+func normalizeList(values []string) string {
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+
+	unique := sorted[:0]
+	for i, value := range sorted {
+		if i == 0 || value != sorted[i-1] {
+			unique = append(unique, value)
+		}
+	}
+	return fmt.Sprintf("%q", unique)
+}
+
+// Function that returns copies of the movements
+// The handlers change the movements they get, which must not change the cached ones
+func cloneMovements(movements []*Movement) []*Movement {
+	clones := make([]*Movement, len(movements))
+	for i, movement := range movements {
+		clones[i] = copyMovement(movement)
+	}
+	return clones
+}
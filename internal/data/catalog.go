@@ -0,0 +1,344 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/arnab4477/Parkour_API/internal/validator"
+	"github.com/lib/pq"
+)
+
+// Constants for what happened to a row of an import
+const (
+	ImportInsert  = "insert"
+	ImportUpdate  = "update"
+	ImportInvalid = "invalid"
+)
+
+// ImportRow struct to hold a movement read from an import file
+// Slug is optional and is used to match the movement when it has been renamed
+type ImportRow struct {
+	Row      int
+	Slug     string
+	Movement *Movement
+}
+
+// ImportResult struct to hold the outcome of a single row of an import
+type ImportResult struct {
+	Row    int               `json:"row"`
+	Name   string            `json:"name"`
+	Action string            `json:"action"`
+	ID     int64             `json:"id,omitempty"`
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// ImportReport struct to hold the outcome of a whole import
+// If any of the rows failed then nothing has been written to the database
+type ImportReport struct {
+	DryRun   bool           `json:"dry_run"`
+	Inserted int            `json:"inserted"`
+	Updated  int            `json:"updated"`
+	Failed   int            `json:"failed"`
+	Rows     []ImportResult `json:"rows"`
+}
+
+// Regular expression that matches everything that is not allowed in a slug
+var slugRegEx = regexp.MustCompile("[^a-z0-9]+")
+
+// Function that returns the url friendly version of a movement name
+// For example "Push Up" and "push-up" both become "push-up"
+func Slugify(name string) string {
+	return strings.Trim(slugRegEx.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// Method for getting every movement in the catalog sorted by id
+func (m MovementModel) ExportMovements(ctx context.Context) ([]*Movement, error) {
+	ctx, cancel := queryContext(ctx, m.Timeout)
+	defer cancel()
+
+	query := `
+		SELECT id, createdAt, name, description, image, tutorials, skilltype, muscles, difficulty, equipments, prerequisite, version
+		FROM movements
+		ORDER BY id ASC`
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	movements := []*Movement{}
+
+	for rows.Next() {
+		var movement Movement
+
+		err := rows.Scan(
+			&movement.ID,
+			&movement.CreatedAt,
+			&movement.Name,
+			&movement.Description,
+			&movement.Image,
+			pq.Array(&movement.Tutorials),
+			pq.Array(&movement.Skilltype),
+			pq.Array(&movement.Muscles),
+			&movement.Difficulty,
+			pq.Array(&movement.Equipments),
+			pq.Array(&movement.Prerequisites),
+			&movement.Version,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		movements = append(movements, &movement)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return movements, nil
+}
+
+// CatalogStats struct to hold the summary of the movement catalog
+type CatalogStats struct {
+	Movements    int            `json:"movements"`
+	Difficulties map[string]int `json:"difficulties"`
+	Skilltypes   map[string]int `json:"skilltypes"`
+	Equipments   map[string]int `json:"equipments"`
+	LastChange   *time.Time     `json:"last_change"`
+}
+
+// Method for getting the summary of the movement catalog
+func (m MovementModel) GetCatalogStats(ctx context.Context) (*CatalogStats, error) {
+	ctx, cancel := queryContext(ctx, m.Timeout)
+	defer cancel()
+
+	stats := &CatalogStats{}
+
+	// SQL query to count the movements and find the time of the last change
+	query := `
+		SELECT (SELECT count(*) FROM movements), (SELECT max(changed_at) FROM movement_changes)`
+
+	err := m.DB.QueryRowContext(ctx, query).Scan(&stats.Movements, &stats.LastChange)
+	if err != nil {
+		return nil, err
+	}
+
+	// Count the movements for every value of the difficulty and of the array fields
+	stats.Difficulties, err = m.countValues(ctx, `SELECT LOWER(difficulty), count(*) FROM movements GROUP BY 1`)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.Skilltypes, err = m.countValues(ctx, `SELECT unnest(skilltype), count(*) FROM movements GROUP BY 1`)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.Equipments, err = m.countValues(ctx, `SELECT unnest(equipments), count(*) FROM movements GROUP BY 1`)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// Method that runs a query returning (value, count) rows and collects them in a map
+func (m MovementModel) countValues(ctx context.Context, query string) (map[string]int, error) {
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+
+	for rows.Next() {
+		var value string
+		var count int
+
+		err := rows.Scan(&value, &count)
+		if err != nil {
+			return nil, err
+		}
+
+		counts[value] = count
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// Method for inserting or updating many movements in a single transaction
+// Every row is matched against the existing movements by its slug or its name
+// Nothing is written if any row fails validation or if dryRun is true
+// A large import runs many statements, so only ctx limits it and not the query timeout
+func (m MovementModel) ImportMovements(ctx context.Context, rows []ImportRow, dryRun bool) (*ImportReport, error) {
+	report := validateImport(rows, dryRun)
+
+	// Use a transaction and, if the rows are going to be written, lock the movements
+	// table so that no other request can create a movement with the same name meanwhile
+	err := withTx(ctx, m.DB, func(tx Querier) error {
+		if report.Failed == 0 && !dryRun {
+			_, err := tx.ExecContext(ctx, `LOCK TABLE movements IN SHARE ROW EXCLUSIVE MODE`)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Index the existing movements by their slug
+		existing, err := movementSlugs(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		// Decide for each valid row if it is an insert or an update
+		planImport(report, rows, existing)
+
+		if report.Failed > 0 || dryRun {
+			return nil
+		}
+
+		// Write the rows, recording every change in the change log
+		for i, row := range rows {
+			movement := row.Movement
+			args := []interface{}{movement.Name, movement.Description, movement.Image, pq.Array(movement.Tutorials), pq.Array(movement.Skilltype), pq.Array(movement.Muscles), movement.Difficulty, pq.Array(movement.Equipments), pq.Array(movement.Prerequisites)}
+
+			switch report.Rows[i].Action {
+			case ImportInsert:
+				query := `
+					INSERT INTO movements (name, description, image, tutorials, skilltype, muscles, difficulty, equipments, prerequisite)
+					VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+					RETURNING id, createdAt, version`
+
+				err = tx.QueryRowContext(ctx, query, args...).Scan(&movement.ID, &movement.CreatedAt, &movement.Version)
+				if err != nil {
+					return err
+				}
+
+				report.Rows[i].ID = movement.ID
+				err = recordMovementChange(ctx, tx, movement.ID, ChangeInsert)
+
+			case ImportUpdate:
+				query := `
+					UPDATE movements
+					SET name = $1, description = $2, image = $3, tutorials = $4, skilltype = $5, muscles = $6, difficulty = $7, equipments = $8, prerequisite = $9, version = version + 1
+					WHERE id = $10
+					RETURNING id, createdAt, version`
+
+				args = append(args, report.Rows[i].ID)
+				err = tx.QueryRowContext(ctx, query, args...).Scan(&movement.ID, &movement.CreatedAt, &movement.Version)
+				if err != nil {
+					return err
+				}
+
+				err = recordMovementChange(ctx, tx, movement.ID, ChangeUpdate)
+			}
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// Function that validates every row of an import so that all the errors are reported at once
+// Rows without a slug get the slug of their name
+func validateImport(rows []ImportRow, dryRun bool) *ImportReport {
+	report := &ImportReport{DryRun: dryRun, Rows: make([]ImportResult, len(rows))}
+
+	seen := make(map[string]int)
+	for i, row := range rows {
+		result := ImportResult{Row: row.Row, Name: row.Movement.Name}
+
+		if rows[i].Slug == "" {
+			rows[i].Slug = Slugify(row.Movement.Name)
+		}
+
+		v := validator.NewValidator()
+		ValidateMovement(v, row.Movement)
+		if previous, exists := seen[rows[i].Slug]; exists && rows[i].Slug != "" {
+			v.AddError("name", fmt.Sprintf("duplicates the movement in row %d", previous))
+		}
+		seen[rows[i].Slug] = row.Row
+
+		if !v.NoErrors() {
+			result.Action = ImportInvalid
+			result.Errors = v.Errors
+			report.Failed++
+		}
+
+		report.Rows[i] = result
+	}
+
+	return report
+}
+
+// Function that decides for each valid row of an import if it is an insert or an update
+// existing holds the ids of the existing movements keyed by the slug of their name
+func planImport(report *ImportReport, rows []ImportRow, existing map[string]int64) {
+	report.Inserted, report.Updated = 0, 0
+
+	for i, row := range rows {
+		if report.Rows[i].Action == ImportInvalid {
+			continue
+		}
+
+		id, exists := existing[row.Slug]
+		if !exists {
+			id, exists = existing[Slugify(row.Movement.Name)]
+		}
+
+		if exists {
+			report.Rows[i].Action = ImportUpdate
+			report.Rows[i].ID = id
+			report.Updated++
+		} else {
+			report.Rows[i].Action = ImportInsert
+			report.Inserted++
+		}
+	}
+}
+
+// Function that returns the ids of all the movements keyed by the slug of their name
+func movementSlugs(ctx context.Context, tx Querier) (map[string]int64, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id, name FROM movements`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	slugs := make(map[string]int64)
+
+	for rows.Next() {
+		var id int64
+		var name string
+
+		err := rows.Scan(&id, &name)
+		if err != nil {
+			return nil, err
+		}
+
+		slugs[Slugify(name)] = id
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return slugs, nil
+}
@@ -0,0 +1,128 @@
+package data
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
+)
+
+// Constants for the operations recorded in the movement change log
+const (
+	ChangeInsert = "insert"
+	ChangeUpdate = "update"
+	ChangeDelete = "delete"
+)
+
+// Custom error for a sync token that could not be decoded
+var ErrInvalidSyncToken = errors.New("invalid sync token")
+
+// MovementChanges struct to hold the ids of the movements that changed since a sync token
+// along with the token the client has to send to get the next batch of changes
+type MovementChanges struct {
+	Inserted  []int64 `json:"inserted"`
+	Updated   []int64 `json:"updated"`
+	Deleted   []int64 `json:"deleted"`
+	NextToken string  `json:"next_token"`
+}
+
+// Function that encodes a position in the change log as an opaque sync token
+func EncodeSyncToken(position int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(position, 10)))
+}
+
+// Function that decodes a sync token back to a position in the change log
+// An empty token means that the client has never synced before
+func DecodeSyncToken(token string) (int64, error) {
+	if token == "" {
+		return 0, nil
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, ErrInvalidSyncToken
+	}
+
+	position, err := strconv.ParseInt(string(decoded), 10, 64)
+	if err != nil || position < 0 {
+		return 0, ErrInvalidSyncToken
+	}
+
+	return position, nil
+}
+
+// Function that adds an entry to the movement change log
+// It must be called with the same transaction that changed the movement so that
+// the change log can never disagree with the movements table
+func recordMovementChange(ctx context.Context, tx Querier, movementID int64, operation string) error {
+	query := `
+		INSERT INTO movement_changes (movement_id, operation)
+		VALUES ($1, $2)`
+
+	_, err := tx.ExecContext(ctx, query, movementID, operation)
+	return err
+}
+
+// Method for getting the ids of all the movements that changed since a sync token position
+func (m MovementModel) GetMovementChanges(ctx context.Context, since int64) (*MovementChanges, error) {
+	ctx, cancel := queryContext(ctx, m.Timeout)
+	defer cancel()
+
+	// Every change is stamped with the id of the transaction that made it. Ids below the xmin
+	// of the current snapshot belong to transactions that have already finished, so using it
+	// as the next position guarantees that a change committed late is never skipped
+	var until int64
+	err := m.DB.QueryRowContext(ctx, `SELECT txid_snapshot_xmin(txid_current_snapshot())`).Scan(&until)
+	if err != nil {
+		return nil, err
+	}
+
+	// The first and the last operation for each movement decide which list it goes to
+	// For example a movement that was inserted and then updated is only reported as inserted
+	query := `
+		SELECT movement_id,
+			(array_agg(operation ORDER BY id ASC))[1],
+			(array_agg(operation ORDER BY id DESC))[1]
+		FROM movement_changes
+		WHERE txid >= $1 AND txid < $2
+		GROUP BY movement_id
+		ORDER BY movement_id ASC`
+
+	rows, err := m.DB.QueryContext(ctx, query, since, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := &MovementChanges{
+		Inserted:  []int64{},
+		Updated:   []int64{},
+		Deleted:   []int64{},
+		NextToken: EncodeSyncToken(until),
+	}
+
+	for rows.Next() {
+		var movementID int64
+		var firstOperation, lastOperation string
+
+		err := rows.Scan(&movementID, &firstOperation, &lastOperation)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case lastOperation == ChangeDelete:
+			changes.Deleted = append(changes.Deleted, movementID)
+		case firstOperation == ChangeInsert:
+			changes.Inserted = append(changes.Inserted, movementID)
+		default:
+			changes.Updated = append(changes.Updated, movementID)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
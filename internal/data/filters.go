@@ -12,6 +12,9 @@ type Filters struct {
 	Page         int
 	PageSize     int
 	SortSafeList []string
+	// The fields to select, all of them if it is empty
+	Fields         []string
+	FieldsSafeList []string
 }
 
 // Function that validates the filters of the query parameters
@@ -26,6 +29,30 @@ func ValidateFilters(v *validator.Validator, f Filters) {
 	v.Check(f.Page > 10_000_000, "page", "must be lower than 10 million")
 	v.Check(f.PageSize < 0, "page_size", "must be greater than zero")
 	v.Check(f.PageSize > 100, "page_size", "must be lower than one hundred")
+
+	ValidateFields(v, f.Fields, f.FieldsSafeList)
+}
+
+// Function that validates the fields query parameter against the fields that can be picked
+func ValidateFields(v *validator.Validator, fields []string, safeList []string) {
+	for _, field := range fields {
+		v.Check(!validator.In(field, safeList...), "fields", "invalid field "+field)
+	}
+	v.Check(!validator.IsUnique(fields), "fields", "must not contain duplicate values")
+}
+
+// Return the columns to select, checking each of them against the safe list just like sortColumns
+func (f Filters) selectColumns() string {
+	if len(f.Fields) == 0 {
+		return "*"
+	}
+
+	for _, field := range f.Fields {
+		if !validator.In(field, f.FieldsSafeList...) {
+			panic("unsafe field parameter: " + field)
+		}
+	}
+	return strings.Join(f.Fields, ", ")
 }
 
 // Check that the sort parameters re valid
@@ -51,7 +78,7 @@ func (f Filters) sortDirection() string {
 // Calculate the limit and the offset from the
 // Given parameters "page" and "page_size"
 func (f Filters) limit() int {
-	return f.Page
+	return f.PageSize
 }
 
 func (f Filters) offset() int {
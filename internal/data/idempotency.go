@@ -0,0 +1,146 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// IdempotencyKey identifies the requests that are retries of each other
+// The key is only unique for a user and a route, so that a client can't replay the response of another
+type IdempotencyKey struct {
+	Key    string
+	UserID int64 // 0 for the requests without a bearer token
+	// The IP address of the requests without a bearer token, so that the anonymous clients don't share their keys
+	Client string
+	Route  string
+}
+
+// IdempotencyRecord holds a request made with an Idempotency-Key and, once it is done, its response
+type IdempotencyRecord struct {
+	IdempotencyKey
+	// Hash of the request, a retry with the same key must send the same request
+	Fingerprint string
+	ExpiresAt   time.Time
+
+	// The response, which is only set once the request is done
+	Completed bool
+	Status    int
+	Header    map[string]string
+	Body      []byte
+}
+
+// The idempotency model type that warps a database connection
+type IdempotencyModel struct {
+	DB      Querier
+	Timeout time.Duration
+}
+
+// Method that reserves the key of record for its request
+// If the key is already in use it returns the record that holds it instead, with its response
+// if the request is done, and nil once the key is reserved
+// A key whose record has expired can be reserved again
+func (m IdempotencyModel) ReserveIdempotencyKey(ctx context.Context, record *IdempotencyRecord) (*IdempotencyRecord, error) {
+	ctx, cancel := queryContext(ctx, m.Timeout)
+	defer cancel()
+
+	insert := `
+		INSERT INTO idempotency_keys (key, user_id, client, route, fingerprint, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (key, user_id, client, route) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint, status = NULL, header = NULL, body = NULL, expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= $7
+		RETURNING key`
+
+	query := `
+		SELECT fingerprint, status, header, body, expires_at
+		FROM idempotency_keys
+		WHERE key = $1 AND user_id = $2 AND client = $3 AND route = $4`
+
+	// The record that holds the key can be released between the two queries, then the insert is tried again
+	for attempt := 1; ; attempt++ {
+		var key string
+		err := m.DB.QueryRowContext(ctx, insert, record.Key, record.UserID, record.Client, record.Route, record.Fingerprint, record.ExpiresAt, time.Now()).Scan(&key)
+		if err == nil {
+			return nil, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+
+		existing := IdempotencyRecord{IdempotencyKey: record.IdempotencyKey}
+		var status sql.NullInt32
+		var header []byte
+		err = m.DB.QueryRowContext(ctx, query, record.Key, record.UserID, record.Client, record.Route).Scan(
+			&existing.Fingerprint, &status, &header, &existing.Body, &existing.ExpiresAt,
+		)
+		if errors.Is(err, sql.ErrNoRows) && attempt < 3 {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if status.Valid {
+			existing.Completed = true
+			existing.Status = int(status.Int32)
+			err = json.Unmarshal(header, &existing.Header)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return &existing, nil
+	}
+}
+
+// Method that stores the response of the request that reserved the key of record
+// The key is kept until the ExpiresAt of record, which replaces the one it was reserved with
+func (m IdempotencyModel) CompleteIdempotencyKey(ctx context.Context, record *IdempotencyRecord) error {
+	ctx, cancel := queryContext(ctx, m.Timeout)
+	defer cancel()
+
+	header, err := json.Marshal(record.Header)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE idempotency_keys
+		SET status = $5, header = $6, body = $7, expires_at = $9
+		WHERE key = $1 AND user_id = $2 AND client = $3 AND route = $4 AND fingerprint = $8`
+
+	_, err = m.DB.ExecContext(ctx, query, record.Key, record.UserID, record.Client, record.Route, record.Status, header, record.Body, record.Fingerprint, record.ExpiresAt)
+	return err
+}
+
+// Method that frees a key whose request failed before it was done, so that a retry runs it again
+func (m IdempotencyModel) ReleaseIdempotencyKey(ctx context.Context, key IdempotencyKey) error {
+	ctx, cancel := queryContext(ctx, m.Timeout)
+	defer cancel()
+
+	query := `
+		DELETE FROM idempotency_keys
+		WHERE key = $1 AND user_id = $2 AND client = $3 AND route = $4 AND status IS NULL`
+
+	_, err := m.DB.ExecContext(ctx, query, key.Key, key.UserID, key.Client, key.Route)
+	return err
+}
+
+// Method that deletes all the expired keys and returns how many were deleted
+func (m IdempotencyModel) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	ctx, cancel := queryContext(ctx, m.Timeout)
+	defer cancel()
+
+	query := `
+		DELETE FROM idempotency_keys
+		WHERE expires_at < $1`
+
+	result, err := m.DB.ExecContext(ctx, query, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
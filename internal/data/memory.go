@@ -0,0 +1,894 @@
+package data
+
+import (
+	"context"
+	"crypto/sha256"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// memoryDB holds all the records of the in-memory backend
+// A single mutex guards every table so that operations touching more than one stay consistent
+// It is held for the whole of a transaction, so nothing else can change the tables meanwhile
+type memoryDB struct {
+	mu sync.RWMutex
+
+	movements      map[int64]*Movement
+	lastMovementID int64
+	changes        []memoryChange
+
+	users      map[int64]*User
+	lastUserID int64
+
+	tokens      map[[sha256.Size]byte]*Token
+	permissions map[int64]Permissions
+
+	idempotencyKeys map[IdempotencyKey]*IdempotencyRecord
+}
+
+// memoryChange is an entry of the in-memory movement change log
+type memoryChange struct {
+	position   int64
+	movementID int64
+	operation  string
+	changedAt  time.Time
+}
+
+// The permission codes known to the in-memory backend, like the rows of the permissions table
+var knownPermissions = []string{PermissionMovementsAdmin}
+
+// This returns the Models struct with the models kept in memory
+// Nothing is persisted, so it is only meant for demos and for testing the handlers
+func NewMemoryModels() Models {
+	db := &memoryDB{
+		movements:   make(map[int64]*Movement),
+		users:       make(map[int64]*User),
+		tokens:      make(map[[sha256.Size]byte]*Token),
+		permissions: make(map[int64]Permissions),
+
+		idempotencyKeys: make(map[IdempotencyKey]*IdempotencyRecord),
+	}
+
+	models := db.models(memoryConn{db: db})
+	models.runInTx = db.transaction()
+	return models
+}
+
+// memoryConn is what the memory models use to reach the tables
+// The models of a transaction run while it holds the mutex, so they must not take it again
+type memoryConn struct {
+	db   *memoryDB
+	inTx bool
+}
+
+func (c memoryConn) lock() {
+	if !c.inTx {
+		c.db.mu.Lock()
+	}
+}
+
+func (c memoryConn) unlock() {
+	if !c.inTx {
+		c.db.mu.Unlock()
+	}
+}
+
+func (c memoryConn) rlock() {
+	if !c.inTx {
+		c.db.mu.RLock()
+	}
+}
+
+func (c memoryConn) runlock() {
+	if !c.inTx {
+		c.db.mu.RUnlock()
+	}
+}
+
+// Method that returns the models with all their calls going through conn
+func (db *memoryDB) models(conn memoryConn) Models {
+	return Models{
+		Movements:   memoryMovementModel{conn},
+		Users:       memoryUserModel{conn},
+		Tokens:      memoryTokenModel{conn},
+		Permissions: memoryPermissionModel{conn},
+		Idempotency: memoryIdempotencyModel{conn},
+	}
+}
+
+// Method that returns the function behind Models.Transaction for the in-memory backend
+// The mutex is held while fn runs, so the transactions and the other calls all wait for it to end,
+// and the state is copied before fn runs and put back if fn fails
+func (db *memoryDB) transaction() func(ctx context.Context, fn func(tx Models) error) error {
+	// The models given to fn join the running transaction when Transaction is called on them
+	txModels := db.models(memoryConn{db: db, inTx: true})
+	txModels.runInTx = func(ctx context.Context, fn func(tx Models) error) error {
+		return fn(txModels)
+	}
+
+	return func(ctx context.Context, fn func(tx Models) error) (err error) {
+		db.mu.Lock()
+		defer db.mu.Unlock()
+
+		saved := db.snapshot()
+
+		// Put the state back if fn panics and let the panic carry on to the caller
+		defer func() {
+			if p := recover(); p != nil {
+				db.restore(saved)
+				panic(p)
+			}
+		}()
+
+		err = fn(txModels)
+		if err != nil {
+			db.restore(saved)
+		}
+		return err
+	}
+}
+
+// Method that returns a deep copy of all the tables, the mutex must be held
+func (db *memoryDB) snapshot() *memoryDB {
+	saved := &memoryDB{
+		movements:      make(map[int64]*Movement, len(db.movements)),
+		lastMovementID: db.lastMovementID,
+		changes:        append([]memoryChange(nil), db.changes...),
+		users:          make(map[int64]*User, len(db.users)),
+		lastUserID:     db.lastUserID,
+		tokens:         make(map[[sha256.Size]byte]*Token, len(db.tokens)),
+		permissions:    make(map[int64]Permissions, len(db.permissions)),
+
+		idempotencyKeys: make(map[IdempotencyKey]*IdempotencyRecord, len(db.idempotencyKeys)),
+	}
+
+	for id, movement := range db.movements {
+		saved.movements[id] = copyMovement(movement)
+	}
+	for id, user := range db.users {
+		copied := *user
+		saved.users[id] = &copied
+	}
+	for hash, token := range db.tokens {
+		copied := *token
+		saved.tokens[hash] = &copied
+	}
+	for id, permissions := range db.permissions {
+		saved.permissions[id] = append(Permissions(nil), permissions...)
+	}
+	for key, record := range db.idempotencyKeys {
+		saved.idempotencyKeys[key] = copyIdempotencyRecord(record)
+	}
+
+	return saved
+}
+
+// Method that replaces all the tables with the ones of a snapshot, the mutex must be held
+func (db *memoryDB) restore(saved *memoryDB) {
+	db.movements = saved.movements
+	db.lastMovementID = saved.lastMovementID
+	db.changes = saved.changes
+	db.users = saved.users
+	db.lastUserID = saved.lastUserID
+	db.tokens = saved.tokens
+	db.permissions = saved.permissions
+	db.idempotencyKeys = saved.idempotencyKeys
+}
+
+// Function that returns a copy of a movement so callers can never change the stored one
+func copyMovement(movement *Movement) *Movement {
+	copied := *movement
+	copied.Tutorials = copyStrings(movement.Tutorials)
+	copied.Skilltype = copyStrings(movement.Skilltype)
+	copied.Muscles = copyStrings(movement.Muscles)
+	copied.Equipments = copyStrings(movement.Equipments)
+	copied.Prerequisites = copyStrings(movement.Prerequisites)
+	copied.CreatorID = copyID(movement.CreatorID)
+	copied.VariationOf = copyID(movement.VariationOf)
+	return &copied
+}
+
+// Function that returns a copy of an optional id, keeping nil as nil
+func copyID(id *int64) *int64 {
+	if id == nil {
+		return nil
+	}
+	copied := *id
+	return &copied
+}
+
+// Function that returns a copy of a string slice, keeping nil as nil
+func copyStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	return append([]string{}, values...)
+}
+
+// Function that returns true if values contains every item of subset, like the @> operator
+func containsAll(values []string, subset []string) bool {
+	for _, item := range subset {
+		found := false
+		for _, value := range values {
+			if value == item {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Function that splits a text into lower case words
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// Function that approximates the full text search on the movement names
+// Every word of the query has to be a word of the name, without the stemming done by PostgreSQL
+func matchesName(name, query string) bool {
+	return containsAll(words(name), words(query))
+}
+
+// memoryMovementModel is the in-memory implementation of MovementStore
+type memoryMovementModel struct {
+	memoryConn
+}
+
+// Method for getting all the movements matching the filters
+func (m memoryMovementModel) GetAllMovements(
+	ctx context.Context,
+	name string,
+	difficulty string,
+	skilltype []string,
+	muscles []string,
+	equipments []string,
+	filters Filters) ([]*Movement, error) {
+
+	// Resolve the sort column first, this panics on an unsafe value just like the SQL model
+	column := filters.sortColumns()
+	descending := filters.sortDirection() == "DESC"
+
+	m.rlock()
+	defer m.runlock()
+
+	movements := []*Movement{}
+	for _, movement := range m.db.movements {
+		if name != "" && !matchesName(movement.Name, name) {
+			continue
+		}
+		if difficulty != "" && !strings.EqualFold(movement.Difficulty, difficulty) {
+			continue
+		}
+		if !containsAll(movement.Skilltype, skilltype) || !containsAll(movement.Muscles, muscles) || !containsAll(movement.Equipments, equipments) {
+			continue
+		}
+
+		movements = append(movements, copyMovement(movement))
+	}
+
+	// Sort by the given column and then by id, like "ORDER BY column direction, id ASC"
+	sort.Slice(movements, func(i, j int) bool {
+		var a, b string
+		switch column {
+		case "name":
+			a, b = movements[i].Name, movements[j].Name
+		case "difficulty":
+			a, b = movements[i].Difficulty, movements[j].Difficulty
+		}
+
+		if a != b {
+			if descending {
+				return a > b
+			}
+			return a < b
+		}
+
+		if column == "id" && descending {
+			return movements[i].ID > movements[j].ID
+		}
+		return movements[i].ID < movements[j].ID
+	})
+
+	// Apply the limit and the offset
+	offset := filters.offset()
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(movements) {
+		return []*Movement{}, nil
+	}
+
+	end := offset + filters.limit()
+	if end > len(movements) {
+		end = len(movements)
+	}
+
+	return movements[offset:end], nil
+}
+
+// Method for inserting a new movement
+func (m memoryMovementModel) InsertOneMovement(ctx context.Context, movement *Movement) error {
+	m.lock()
+	defer m.unlock()
+
+	m.db.insertMovement(movement)
+	return nil
+}
+
+// Method for getting a specific movement
+func (m memoryMovementModel) GetOneMovement(ctx context.Context, id int64) (*Movement, error) {
+	if id < 1 {
+		return nil, ErrNotFound
+	}
+
+	m.rlock()
+	defer m.runlock()
+
+	movement, exists := m.db.movements[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	return copyMovement(movement), nil
+}
+
+// Method for updating a movement, only if its version has not changed since it was read
+func (m memoryMovementModel) UpdateOneMovement(ctx context.Context, movement *Movement) error {
+	m.lock()
+	defer m.unlock()
+
+	stored, exists := m.db.movements[movement.ID]
+	if !exists || stored.Version != movement.Version {
+		return ErrEditConflict
+	}
+
+	m.db.updateMovement(stored.ID, movement)
+	return nil
+}
+
+// Method for deleting a movement
+func (m memoryMovementModel) DeleteOneMovement(ctx context.Context, id int64) error {
+	if id < 1 {
+		return ErrNotFound
+	}
+
+	m.lock()
+	defer m.unlock()
+
+	if _, exists := m.db.movements[id]; !exists {
+		return ErrNotFound
+	}
+
+	delete(m.db.movements, id)
+	m.db.recordChange(id, ChangeDelete)
+
+	// The variations of the movement are kept, like ON DELETE SET NULL
+	for _, movement := range m.db.movements {
+		if movement.VariationOf != nil && *movement.VariationOf == id {
+			movement.VariationOf = nil
+		}
+	}
+	return nil
+}
+
+// Method for getting the ids of all the movements that changed since a sync token position
+// The positions are the indexes of the change log, starting at 1
+func (m memoryMovementModel) GetMovementChanges(ctx context.Context, since int64) (*MovementChanges, error) {
+	m.rlock()
+	defer m.runlock()
+
+	until := int64(len(m.db.changes)) + 1
+	changes := &MovementChanges{
+		Inserted:  []int64{},
+		Updated:   []int64{},
+		Deleted:   []int64{},
+		NextToken: EncodeSyncToken(until),
+	}
+
+	// Find the first and the last operation for each movement
+	first := make(map[int64]string)
+	last := make(map[int64]string)
+	for _, change := range m.db.changes {
+		if change.position < since {
+			continue
+		}
+		if _, exists := first[change.movementID]; !exists {
+			first[change.movementID] = change.operation
+		}
+		last[change.movementID] = change.operation
+	}
+
+	ids := make([]int64, 0, len(last))
+	for id := range last {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for _, id := range ids {
+		switch {
+		case last[id] == ChangeDelete:
+			changes.Deleted = append(changes.Deleted, id)
+		case first[id] == ChangeInsert:
+			changes.Inserted = append(changes.Inserted, id)
+		default:
+			changes.Updated = append(changes.Updated, id)
+		}
+	}
+
+	return changes, nil
+}
+
+// Method for getting every movement in the catalog sorted by id
+func (m memoryMovementModel) ExportMovements(ctx context.Context) ([]*Movement, error) {
+	m.rlock()
+	defer m.runlock()
+
+	movements := make([]*Movement, 0, len(m.db.movements))
+	for _, movement := range m.db.movements {
+		movements = append(movements, copyMovement(movement))
+	}
+	sort.Slice(movements, func(i, j int) bool { return movements[i].ID < movements[j].ID })
+
+	return movements, nil
+}
+
+// Method for inserting or updating many movements at once
+// Nothing is written if any row fails validation or if dryRun is true
+func (m memoryMovementModel) ImportMovements(ctx context.Context, rows []ImportRow, dryRun bool) (*ImportReport, error) {
+	report := validateImport(rows, dryRun)
+
+	m.lock()
+	defer m.unlock()
+
+	existing := make(map[string]int64)
+	for _, movement := range m.db.movements {
+		existing[Slugify(movement.Name)] = movement.ID
+	}
+
+	planImport(report, rows, existing)
+
+	if report.Failed > 0 || dryRun {
+		return report, nil
+	}
+
+	for i, row := range rows {
+		switch report.Rows[i].Action {
+		case ImportInsert:
+			m.db.insertMovement(row.Movement)
+			report.Rows[i].ID = row.Movement.ID
+		case ImportUpdate:
+			// The import files have no variations, so the one of the movement is kept
+			row.Movement.VariationOf = copyID(m.db.movements[report.Rows[i].ID].VariationOf)
+			m.db.updateMovement(report.Rows[i].ID, row.Movement)
+		}
+	}
+
+	return report, nil
+}
+
+// Method for getting the movements with any of the given names, compared case insensitively
+func (m memoryMovementModel) GetMovementsByName(ctx context.Context, names []string) ([]*Movement, error) {
+	m.rlock()
+	defer m.runlock()
+
+	movements := []*Movement{}
+	for _, movement := range m.db.movements {
+		for _, name := range names {
+			if strings.EqualFold(movement.Name, name) {
+				movements = append(movements, copyMovement(movement))
+				break
+			}
+		}
+	}
+
+	sortByID(movements)
+	return movements, nil
+}
+
+// Method for getting the variations of any of the given movements
+func (m memoryMovementModel) GetVariations(ctx context.Context, ids []int64) ([]*Movement, error) {
+	m.rlock()
+	defer m.runlock()
+
+	movements := []*Movement{}
+	for _, movement := range m.db.movements {
+		if movement.VariationOf == nil {
+			continue
+		}
+		for _, id := range ids {
+			if *movement.VariationOf == id {
+				movements = append(movements, copyMovement(movement))
+				break
+			}
+		}
+	}
+
+	sortByID(movements)
+	return movements, nil
+}
+
+// Method for getting the movements with any of the given ids
+func (m memoryMovementModel) GetMovementsByID(ctx context.Context, ids []int64) ([]*Movement, error) {
+	m.rlock()
+	defer m.runlock()
+
+	movements := []*Movement{}
+	seen := make(map[int64]bool)
+	for _, id := range ids {
+		if movement, exists := m.db.movements[id]; exists && !seen[id] {
+			seen[id] = true
+			movements = append(movements, copyMovement(movement))
+		}
+	}
+
+	sortByID(movements)
+	return movements, nil
+}
+
+// Function that sorts movements by id, like "ORDER BY id"
+func sortByID(movements []*Movement) {
+	sort.Slice(movements, func(i, j int) bool { return movements[i].ID < movements[j].ID })
+}
+
+// Method for getting the summary of the movement catalog
+func (m memoryMovementModel) GetCatalogStats(ctx context.Context) (*CatalogStats, error) {
+	m.rlock()
+	defer m.runlock()
+
+	stats := &CatalogStats{
+		Movements:    len(m.db.movements),
+		Difficulties: make(map[string]int),
+		Skilltypes:   make(map[string]int),
+		Equipments:   make(map[string]int),
+	}
+
+	for _, movement := range m.db.movements {
+		stats.Difficulties[strings.ToLower(movement.Difficulty)]++
+		for _, skilltype := range movement.Skilltype {
+			stats.Skilltypes[skilltype]++
+		}
+		for _, equipment := range movement.Equipments {
+			stats.Equipments[equipment]++
+		}
+	}
+
+	if len(m.db.changes) > 0 {
+		lastChange := m.db.changes[len(m.db.changes)-1].changedAt
+		stats.LastChange = &lastChange
+	}
+
+	return stats, nil
+}
+
+// Method that stores a new movement and fills in its system generated fields
+// The caller must hold the write lock
+func (db *memoryDB) insertMovement(movement *Movement) {
+	db.lastMovementID++
+	movement.ID = db.lastMovementID
+	movement.CreatedAt = time.Now().Truncate(time.Second)
+	movement.Version = 1
+
+	db.movements[movement.ID] = copyMovement(movement)
+	db.recordChange(movement.ID, ChangeInsert)
+}
+
+// Method that replaces the stored movement with the given id and increments its version
+// The caller must hold the write lock
+func (db *memoryDB) updateMovement(id int64, movement *Movement) {
+	stored := db.movements[id]
+
+	movement.ID = id
+	movement.CreatedAt = stored.CreatedAt
+	movement.CreatorID = copyID(stored.CreatorID)
+	movement.Version = stored.Version + 1
+
+	db.movements[id] = copyMovement(movement)
+	db.recordChange(id, ChangeUpdate)
+}
+
+// Method that adds an entry to the change log
+// The caller must hold the write lock
+func (db *memoryDB) recordChange(movementID int64, operation string) {
+	db.changes = append(db.changes, memoryChange{
+		position:   int64(len(db.changes)) + 1,
+		movementID: movementID,
+		operation:  operation,
+		changedAt:  time.Now().Truncate(time.Second),
+	})
+}
+
+// memoryUserModel is the in-memory implementation of UserStore
+type memoryUserModel struct {
+	memoryConn
+}
+
+// Method that returns the stored user with the given email, compared case insensitively like citext
+// The caller must hold the lock
+func (db *memoryDB) userByEmail(email string) *User {
+	for _, user := range db.users {
+		if strings.EqualFold(user.Email, email) {
+			return user
+		}
+	}
+	return nil
+}
+
+// Method to insert a new user
+func (m memoryUserModel) InsertOneUser(ctx context.Context, user *User) error {
+	m.lock()
+	defer m.unlock()
+
+	if m.db.userByEmail(user.Email) != nil {
+		return ErrDuplicateEmail
+	}
+
+	m.db.lastUserID++
+	user.ID = m.db.lastUserID
+	user.Version = 1
+
+	stored := *user
+	m.db.users[user.ID] = &stored
+	return nil
+}
+
+// Method to get one user by an unique email
+func (m memoryUserModel) GetOneUserByEmail(ctx context.Context, email string) (*User, error) {
+	m.rlock()
+	defer m.runlock()
+
+	user := m.db.userByEmail(email)
+	if user == nil {
+		return nil, ErrNotFound
+	}
+
+	found := *user
+	return &found, nil
+}
+
+// Method to get the users with any of the given ids
+func (m memoryUserModel) GetUsersByID(ctx context.Context, ids []int64) ([]*User, error) {
+	m.rlock()
+	defer m.runlock()
+
+	users := []*User{}
+	for _, id := range ids {
+		if user, exists := m.db.users[id]; exists {
+			found := *user
+			users = append(users, &found)
+		}
+	}
+
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+// Method to update one user, only if its version has not changed since it was read
+func (m memoryUserModel) UpdateOneUser(ctx context.Context, user *User) error {
+	m.lock()
+	defer m.unlock()
+
+	if other := m.db.userByEmail(user.Email); other != nil && other.ID != user.ID {
+		return ErrDuplicateEmail
+	}
+
+	stored, exists := m.db.users[user.ID]
+	if !exists || stored.Version != user.Version {
+		return ErrEditConflict
+	}
+
+	user.Version++
+	updated := *user
+	m.db.users[user.ID] = &updated
+	return nil
+}
+
+// Method to get one user by a token that has not expired
+func (m memoryUserModel) GetUserFromToken(ctx context.Context, tokenScope, tokenPlaintext string) (*User, error) {
+	tokenHash := sha256.Sum256([]byte(tokenPlaintext))
+
+	m.rlock()
+	defer m.runlock()
+
+	token, exists := m.db.tokens[tokenHash]
+	if !exists || token.Scope != tokenScope || !token.Expiry.After(time.Now()) {
+		return nil, ErrNotFound
+	}
+
+	user, exists := m.db.users[token.User_id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	found := *user
+	return &found, nil
+}
+
+// memoryTokenModel is the in-memory implementation of TokenStore
+type memoryTokenModel struct {
+	memoryConn
+}
+
+// Method to add a token
+func (m memoryTokenModel) InsertOneToken(ctx context.Context, token *Token) error {
+	m.lock()
+	defer m.unlock()
+
+	var hash [sha256.Size]byte
+	copy(hash[:], token.Hash)
+
+	stored := *token
+	stored.PlainText = ""
+	m.db.tokens[hash] = &stored
+	return nil
+}
+
+// Shortcut method that creates a new token and stores it
+func (m memoryTokenModel) NewToken(ctx context.Context, userID int64, ttl time.Duration, scope string) (*Token, error) {
+	token, err := generateToken(userID, ttl, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	err = m.InsertOneToken(ctx, token)
+	return token, err
+}
+
+// Method to delete all tokens for a specific user and scope
+func (m memoryTokenModel) DeleteTokens(ctx context.Context, userID int64, scope string) error {
+	m.lock()
+	defer m.unlock()
+
+	for hash, token := range m.db.tokens {
+		if token.User_id == userID && token.Scope == scope {
+			delete(m.db.tokens, hash)
+		}
+	}
+	return nil
+}
+
+// Method to delete all tokens of every scope for a specific user
+func (m memoryTokenModel) DeleteAllTokensForUser(ctx context.Context, userID int64) error {
+	m.lock()
+	defer m.unlock()
+
+	for hash, token := range m.db.tokens {
+		if token.User_id == userID {
+			delete(m.db.tokens, hash)
+		}
+	}
+	return nil
+}
+
+// Method to delete all the expired tokens and return how many were deleted
+func (m memoryTokenModel) DeleteExpiredTokens(ctx context.Context) (int64, error) {
+	m.lock()
+	defer m.unlock()
+
+	var deleted int64
+	now := time.Now()
+	for hash, token := range m.db.tokens {
+		if token.Expiry.Before(now) {
+			delete(m.db.tokens, hash)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// memoryPermissionModel is the in-memory implementation of PermissionStore
+type memoryPermissionModel struct {
+	memoryConn
+}
+
+// Method to get all the permission codes of a specific user
+func (m memoryPermissionModel) GetAllForUser(ctx context.Context, userID int64) (Permissions, error) {
+	m.rlock()
+	defer m.runlock()
+
+	return append(Permissions(nil), m.db.permissions[userID]...), nil
+}
+
+// Method to grant permissions to a specific user
+// Unknown codes are ignored, like the codes missing from the permissions table
+func (m memoryPermissionModel) AddForUser(ctx context.Context, userID int64, codes ...string) error {
+	m.lock()
+	defer m.unlock()
+
+	for _, code := range codes {
+		known := false
+		for _, knownCode := range knownPermissions {
+			if code == knownCode {
+				known = true
+			}
+		}
+		if known && !m.db.permissions[userID].Include(code) {
+			m.db.permissions[userID] = append(m.db.permissions[userID], code)
+		}
+	}
+	return nil
+}
+
+// memoryIdempotencyModel is the in-memory implementation of IdempotencyStore
+type memoryIdempotencyModel struct {
+	memoryConn
+}
+
+// Method that reserves the key of record, or returns the record that holds it
+func (m memoryIdempotencyModel) ReserveIdempotencyKey(ctx context.Context, record *IdempotencyRecord) (*IdempotencyRecord, error) {
+	m.lock()
+	defer m.unlock()
+
+	if existing, exists := m.db.idempotencyKeys[record.IdempotencyKey]; exists && existing.ExpiresAt.After(time.Now()) {
+		return copyIdempotencyRecord(existing), nil
+	}
+
+	reserved := copyIdempotencyRecord(record)
+	reserved.Completed, reserved.Status, reserved.Header, reserved.Body = false, 0, nil, nil
+	m.db.idempotencyKeys[record.IdempotencyKey] = reserved
+	return nil, nil
+}
+
+// Method that stores the response of the request that reserved the key of record
+func (m memoryIdempotencyModel) CompleteIdempotencyKey(ctx context.Context, record *IdempotencyRecord) error {
+	m.lock()
+	defer m.unlock()
+
+	stored, exists := m.db.idempotencyKeys[record.IdempotencyKey]
+	if !exists || stored.Fingerprint != record.Fingerprint {
+		return nil
+	}
+
+	completed := copyIdempotencyRecord(record)
+	completed.Completed = true
+	m.db.idempotencyKeys[record.IdempotencyKey] = completed
+	return nil
+}
+
+// Method that frees a key whose request is not done
+func (m memoryIdempotencyModel) ReleaseIdempotencyKey(ctx context.Context, key IdempotencyKey) error {
+	m.lock()
+	defer m.unlock()
+
+	if stored, exists := m.db.idempotencyKeys[key]; exists && !stored.Completed {
+		delete(m.db.idempotencyKeys, key)
+	}
+	return nil
+}
+
+// Method that deletes all the expired keys and returns how many were deleted
+func (m memoryIdempotencyModel) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	m.lock()
+	defer m.unlock()
+
+	var deleted int64
+	now := time.Now()
+	for key, record := range m.db.idempotencyKeys {
+		if record.ExpiresAt.Before(now) {
+			delete(m.db.idempotencyKeys, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// Function that returns a copy of a record so callers can never change the stored one
+func copyIdempotencyRecord(record *IdempotencyRecord) *IdempotencyRecord {
+	copied := *record
+	copied.Body = append([]byte(nil), record.Body...)
+	if record.Header != nil {
+		copied.Header = make(map[string]string, len(record.Header))
+		for name, value := range record.Header {
+			copied.Header[name] = value
+		}
+	}
+	return &copied
+}
@@ -1,29 +1,106 @@
 package data
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 // Custom errors
 var (
 	ErrNotFound       = errors.New("record not found")
-	ErrEditConflict   = errors.New("record not found")
+	ErrEditConflict   = errors.New("edit conflict")
 	ErrDuplicateEmail = errors.New("diplicate email")
 )
 
+// MovementStore is the interface for storing and querying movements
+type MovementStore interface {
+	GetAllMovements(ctx context.Context, name string, difficulty string, skilltype []string, muscles []string, equipments []string, filters Filters) ([]*Movement, error)
+	InsertOneMovement(ctx context.Context, movement *Movement) error
+	GetOneMovement(ctx context.Context, id int64) (*Movement, error)
+	UpdateOneMovement(ctx context.Context, movement *Movement) error
+	DeleteOneMovement(ctx context.Context, id int64) error
+	GetMovementChanges(ctx context.Context, since int64) (*MovementChanges, error)
+	ExportMovements(ctx context.Context) ([]*Movement, error)
+	ImportMovements(ctx context.Context, rows []ImportRow, dryRun bool) (*ImportReport, error)
+	GetCatalogStats(ctx context.Context) (*CatalogStats, error)
+	GetMovementsByName(ctx context.Context, names []string) ([]*Movement, error)
+	GetVariations(ctx context.Context, ids []int64) ([]*Movement, error)
+	GetMovementsByID(ctx context.Context, ids []int64) ([]*Movement, error)
+}
+
+// UserStore is the interface for storing and querying users
+type UserStore interface {
+	InsertOneUser(ctx context.Context, user *User) error
+	GetOneUserByEmail(ctx context.Context, email string) (*User, error)
+	UpdateOneUser(ctx context.Context, user *User) error
+	GetUserFromToken(ctx context.Context, tokenScope, tokenPlaintext string) (*User, error)
+	GetUsersByID(ctx context.Context, ids []int64) ([]*User, error)
+}
+
+// TokenStore is the interface for storing and deleting tokens
+type TokenStore interface {
+	InsertOneToken(ctx context.Context, token *Token) error
+	NewToken(ctx context.Context, userID int64, ttl time.Duration, scope string) (*Token, error)
+	DeleteTokens(ctx context.Context, userID int64, scope string) error
+	DeleteAllTokensForUser(ctx context.Context, userID int64) error
+	DeleteExpiredTokens(ctx context.Context) (int64, error)
+}
+
+// PermissionStore is the interface for granting and querying permissions
+type PermissionStore interface {
+	GetAllForUser(ctx context.Context, userID int64) (Permissions, error)
+	AddForUser(ctx context.Context, userID int64, codes ...string) error
+}
+
+// IdempotencyStore is the interface for storing the requests made with an Idempotency-Key and their responses
+type IdempotencyStore interface {
+	ReserveIdempotencyKey(ctx context.Context, record *IdempotencyRecord) (*IdempotencyRecord, error)
+	CompleteIdempotencyKey(ctx context.Context, record *IdempotencyRecord) error
+	ReleaseIdempotencyKey(ctx context.Context, key IdempotencyKey) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+}
+
+// Function that reports if err was caused by a cancelled or timed out context
+// This includes the statements that PostgreSQL cancelled because their context was done
+func IsCanceled(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "57014" {
+		return true
+	}
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// Function that returns a context which is cancelled after the query timeout
+// A zero timeout means that the queries only stop when ctx itself is cancelled
+func queryContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
 // This Models struct all the models in the database
 type Models struct {
-	Movements MovementModel
-	Users     UserModel
-	Tokens    TokenModel
+	Movements   MovementStore
+	Users       UserStore
+	Tokens      TokenStore
+	Permissions PermissionStore
+	Idempotency IdempotencyStore
+
+	// The function behind the Transaction method, which depends on the backend
+	runInTx func(ctx context.Context, fn func(tx Models) error) error
 }
 
-// This returns the Models struct with the initialized movement model
-func NewModels(db *sql.DB) Models {
-	return Models{
-		Movements: MovementModel{DB: db},
-		Users:     UserModel{DB: db},
-		Tokens:    TokenModel{DB: db},
+// This returns the Models struct with the models backed by PostgreSQL
+// Every call to the database is cancelled if it takes longer than queryTimeout
+func NewModels(db *sql.DB, queryTimeout time.Duration) Models {
+	models := newSQLModels(db, queryTimeout)
+	models.runInTx = func(ctx context.Context, fn func(tx Models) error) error {
+		return runInSQLTx(ctx, db, queryTimeout, fn)
 	}
+	return models
 }
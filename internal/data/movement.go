@@ -1,9 +1,11 @@
 package data
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/arnab4477/Parkour_API/internal/validator"
@@ -23,7 +25,113 @@ type Movement struct {
 	Difficulty    string    `json:"difficulty"` // Beginner, Intermediate or Advance
 	Equipments    []string  `json:"equipments"`
 	Prerequisites []string  `json:"prerequisite"`
-	Version       int32     `json:"version"` // Version will start at 1 and will be incremented each time the struct is updated
+	Version       int32     `json:"version"`      // Version will start at 1 and will be incremented each time the struct is updated
+	CreatorID     *int64    `json:"creator_id"`   // The user who added the movement, nil for the imported ones
+	VariationOf   *int64    `json:"variation_of"` // The movement this one is a variation of, if any
+}
+
+// The JSON fields of a movement that can be picked with the fields query parameter
+// The columns of the movements table have the same names
+var MovementFields = []string{
+	"id", "name", "description", "image", "tutorials", "skilltype",
+	"muscles", "difficulty", "equipments", "prerequisite", "version",
+	"creator_id", "variation_of",
+}
+
+// Method that returns the value of one of the MovementFields, or nil if there is no such field
+func (movement *Movement) Field(name string) interface{} {
+	switch name {
+	case "id":
+		return movement.ID
+	case "name":
+		return movement.Name
+	case "description":
+		return movement.Description
+	case "image":
+		return movement.Image
+	case "tutorials":
+		return movement.Tutorials
+	case "skilltype":
+		return movement.Skilltype
+	case "muscles":
+		return movement.Muscles
+	case "difficulty":
+		return movement.Difficulty
+	case "equipments":
+		return movement.Equipments
+	case "prerequisite":
+		return movement.Prerequisites
+	case "version":
+		return movement.Version
+	case "creator_id":
+		return movement.CreatorID
+	case "variation_of":
+		return movement.VariationOf
+	default:
+		return nil
+	}
+}
+
+// The columns of a whole movement, in the order of scanTargets without any fields
+// The queries list them rather than SELECT *, which would follow the order of the table
+const movementColumns = "id, createdAt, name, description, image, tutorials, skilltype, muscles, difficulty, equipments, prerequisite, version, creator_id, variation_of"
+
+// Method that returns where to scan the given columns of a row into
+// Without any fields it is the columns of movementColumns
+func (movement *Movement) scanTargets(fields []string) []interface{} {
+	if len(fields) == 0 {
+		return []interface{}{
+			&movement.ID,
+			&movement.CreatedAt,
+			&movement.Name,
+			&movement.Description,
+			&movement.Image,
+			pq.Array(&movement.Tutorials),
+			pq.Array(&movement.Skilltype),
+			pq.Array(&movement.Muscles),
+			&movement.Difficulty,
+			pq.Array(&movement.Equipments),
+			pq.Array(&movement.Prerequisites),
+			&movement.Version,
+			&movement.CreatorID,
+			&movement.VariationOf,
+		}
+	}
+
+	targets := make([]interface{}, len(fields))
+	for i, field := range fields {
+		switch field {
+		case "id":
+			targets[i] = &movement.ID
+		case "name":
+			targets[i] = &movement.Name
+		case "description":
+			targets[i] = &movement.Description
+		case "image":
+			targets[i] = &movement.Image
+		case "tutorials":
+			targets[i] = pq.Array(&movement.Tutorials)
+		case "skilltype":
+			targets[i] = pq.Array(&movement.Skilltype)
+		case "muscles":
+			targets[i] = pq.Array(&movement.Muscles)
+		case "difficulty":
+			targets[i] = &movement.Difficulty
+		case "equipments":
+			targets[i] = pq.Array(&movement.Equipments)
+		case "prerequisite":
+			targets[i] = pq.Array(&movement.Prerequisites)
+		case "version":
+			targets[i] = &movement.Version
+		case "creator_id":
+			targets[i] = &movement.CreatorID
+		case "variation_of":
+			targets[i] = &movement.VariationOf
+		default:
+			panic("unsafe field parameter: " + field)
+		}
+	}
+	return targets
 }
 
 // Check if the input data causes any validation error
@@ -53,36 +161,41 @@ func ValidateMovement(v *validator.Validator, input *Movement) {
 
 // MovementModel struct which warps a SQL connectopn pool
 type MovementModel struct {
-	DB *sql.DB
+	DB      Querier
+	Timeout time.Duration
 }
 
 // Method for getting all the movements from the database
 func (m MovementModel) GetAllMovements(
+	ctx context.Context,
 	name string,
 	difficulty string,
 	skilltype []string,
 	muscles []string,
 	equipments []string,
 	filters Filters) ([]*Movement, error) {
+	ctx, cancel := queryContext(ctx, m.Timeout)
+	defer cancel()
 
 	// SQL query to get all the movements from the database
 	// There is full text search implemented for the name of the movement
 	// For documentation, visit: https://www.postgresql.org/docs/current/datatype-textsearch.html
 	//The movements will be sorted according to the given parameter (if any)
 	// The limit and offset handles the pagination of the returned data
+	// Only the columns of the requested fields are selected, all of them if there are none
 	query := fmt.Sprintf(`
-			SELECT * FROM movements
+			SELECT %s FROM movements
 			WHERE (to_tsvector('english', name) @@ plainto_tsquery('english', $1) OR $1 = '')
 			AND (LOWER(difficulty) = LOWER($2) OR $2 = '')
 			AND (skilltype @> $3 OR $3 = '{}')
 			AND (muscles @> $4 OR $4 = '{}')
 			AND (equipments @> $5 OR $5 = '{}')
 			order by %s %s, id ASC
-			LIMIT %d OFFSET %d`, filters.sortColumns(), filters.sortDirection(),
+			LIMIT %d OFFSET %d`, filters.selectColumns(), filters.sortColumns(), filters.sortDirection(),
 		filters.limit(), filters.offset())
 
 	// Execute the SQL query
-	rows, err := m.DB.Query(
+	rows, err := m.DB.QueryContext(ctx,
 		query, name, difficulty, pq.Array(skilltype),
 		pq.Array(muscles), pq.Array(equipments))
 
@@ -99,20 +212,8 @@ func (m MovementModel) GetAllMovements(
 	for rows.Next() {
 		var movement Movement
 
-		err := rows.Scan(
-			&movement.ID,
-			&movement.CreatedAt,
-			&movement.Name,
-			&movement.Description,
-			&movement.Image,
-			pq.Array(&movement.Tutorials),
-			pq.Array(&movement.Skilltype),
-			pq.Array(&movement.Muscles),
-			&movement.Difficulty,
-			pq.Array(&movement.Equipments),
-			pq.Array(&movement.Prerequisites),
-			&movement.Version,
-		)
+		// Only the fields that were asked for are scanned, the others keep their zero value
+		err := rows.Scan(movement.scanTargets(filters.Fields)...)
 
 		if err != nil {
 			return nil, err
@@ -133,33 +234,47 @@ func (m MovementModel) GetAllMovements(
 }
 
 // Method for inserting a new movement to the movement table
-func (m MovementModel) InsertOneMovement(movement *Movement) error {
+func (m MovementModel) InsertOneMovement(ctx context.Context, movement *Movement) error {
+	ctx, cancel := queryContext(ctx, m.Timeout)
+	defer cancel()
+
 	// SQL query for inserting new record to the Movements table
 	// And returning system generated data
 	query := `
-		INSERT INTO movements (name, description, image, tutorials, skilltype, muscles, difficulty, equipments, prerequisite)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO movements (name, description, image, tutorials, skilltype, muscles, difficulty, equipments, prerequisite, creator_id, variation_of)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING id, createdAt, version`
 
 	// Args slice that holds the values for the placeholders in the SQL query
 	// These values are from the movement struct
 	args :=
-		[]interface{}{movement.Name, movement.Description, movement.Image, pq.Array(movement.Tutorials), pq.Array(movement.Skilltype), pq.Array(movement.Muscles), movement.Difficulty, pq.Array(movement.Equipments), pq.Array(movement.Prerequisites)}
+		[]interface{}{movement.Name, movement.Description, movement.Image, pq.Array(movement.Tutorials), pq.Array(movement.Skilltype), pq.Array(movement.Muscles), movement.Difficulty, pq.Array(movement.Equipments), pq.Array(movement.Prerequisites), movement.CreatorID, movement.VariationOf}
+
+	// Use a transaction so that the movement and its change log entry are written together
+	return withTx(ctx, m.DB, func(tx Querier) error {
+		// Execute the QueryRow() method wuth the query and the args slice as parameters
+		// The Scan() method is used to return the system generated values
+		err := tx.QueryRowContext(ctx, query, args...).Scan(&movement.ID, &movement.CreatedAt, &movement.Version)
+		if err != nil {
+			return err
+		}
 
-	// Execute and return the QueryRow() method wuth the query and the args slice as parameters
-	// The Scan() method is used to return the system generated values
-	return m.DB.QueryRow(query, args...).Scan(&movement.ID, &movement.CreatedAt, &movement.Version)
+		return recordMovementChange(ctx, tx, movement.ID, ChangeInsert)
+	})
 }
 
 // Method for getting a new movement to the movement table
-func (m MovementModel) GetOneMovement(id int64) (*Movement, error) {
+func (m MovementModel) GetOneMovement(ctx context.Context, id int64) (*Movement, error) {
+	ctx, cancel := queryContext(ctx, m.Timeout)
+	defer cancel()
+
 	if id < 1 {
 		return nil, ErrNotFound
 	}
 
 	// The query to fetch data of a specific movement
 	query := `
-		SELECT id, name, description, image, tutorials, skilltype, muscles, difficulty, equipments, prerequisite, version
+		SELECT id, name, description, image, tutorials, skilltype, muscles, difficulty, equipments, prerequisite, version, creator_id, variation_of
 		FROM movements
 		WHERE id = $1`
 
@@ -168,7 +283,7 @@ func (m MovementModel) GetOneMovement(id int64) (*Movement, error) {
 
 	// Execute the query passing in the id parameter
 	// Scan the response data into the fields of the movement struct
-	err := m.DB.QueryRow(query, id).Scan(
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(
 		&movement.ID,
 		&movement.Name,
 		&movement.Description,
@@ -180,6 +295,8 @@ func (m MovementModel) GetOneMovement(id int64) (*Movement, error) {
 		pq.Array(&movement.Equipments),
 		pq.Array(&movement.Prerequisites),
 		&movement.Version,
+		&movement.CreatorID,
+		&movement.VariationOf,
 	)
 
 	// Check if the there is any error regarding the query
@@ -196,12 +313,15 @@ func (m MovementModel) GetOneMovement(id int64) (*Movement, error) {
 }
 
 // Method for updating a new movement to the movement table
-func (m MovementModel) UpdateOneMovement(movement *Movement) error {
+func (m MovementModel) UpdateOneMovement(ctx context.Context, movement *Movement) error {
+	ctx, cancel := queryContext(ctx, m.Timeout)
+	defer cancel()
+
 	// SQL query to update movements in the database
 	query := `
 		UPDATE movements
-		SET name = $1, description = $2, image = $3, tutorials = $4, skilltype = $5, muscles = $6, difficulty = $7, equipments = $8, prerequisite = $9, version = version + 1
-		WHERE id = $10 and version = $11
+		SET name = $1, description = $2, image = $3, tutorials = $4, skilltype = $5, muscles = $6, difficulty = $7, equipments = $8, prerequisite = $9, variation_of = $10, version = version + 1
+		WHERE id = $11 and version = $12
 		RETURNING version`
 
 	// Interface to hold all the placeholder values for the query
@@ -215,28 +335,34 @@ func (m MovementModel) UpdateOneMovement(movement *Movement) error {
 		movement.Difficulty,
 		pq.Array(movement.Equipments),
 		pq.Array(movement.Prerequisites),
+		movement.VariationOf,
 		movement.ID,
 		movement.Version,
 	}
 
-	// Execute the QueryRow method to update the record and scan the version value to the struct
-	err := m.DB.QueryRow(query, args...).Scan(&movement.Version)
-	if err != nil {
-		// If no rows were affected that means there was an edit conflict
-		// Handling this error enables optimistic conurrency locking which avoids
-		// Such edit conflicts in the case of a data race
-		if errors.Is(err, sql.ErrNoRows) {
-			return ErrEditConflict
-		} else {
-			return err
+	// Use a transaction so that the movement and its change log entry are written together
+	return withTx(ctx, m.DB, func(tx Querier) error {
+		// Execute the QueryRow method to update the record and scan the version value to the struct
+		err := tx.QueryRowContext(ctx, query, args...).Scan(&movement.Version)
+		if err != nil {
+			// If no rows were affected that means there was an edit conflict
+			// Handling this error enables optimistic conurrency locking which avoids
+			// Such edit conflicts in the case of a data race
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrEditConflict
+			} else {
+				return err
+			}
 		}
-	}
 
-	return nil
+		return recordMovementChange(ctx, tx, movement.ID, ChangeUpdate)
+	})
 }
 
 // Method for deleting a new movement to the movement table
-func (m MovementModel) DeleteOneMovement(id int64) error {
+func (m MovementModel) DeleteOneMovement(ctx context.Context, id int64) error {
+	ctx, cancel := queryContext(ctx, m.Timeout)
+	defer cancel()
 
 	if id < 1 {
 		return ErrNotFound
@@ -247,20 +373,97 @@ func (m MovementModel) DeleteOneMovement(id int64) error {
 		DELETE FROM movements
 		WHERE id = $1`
 
-	result, err := m.DB.Exec(query, id)
-	if err != nil {
-		return err
+	// Use a transaction so that the deletion and its change log entry are written together
+	return withTx(ctx, m.DB, func(tx Querier) error {
+		result, err := tx.ExecContext(ctx, query, id)
+		if err != nil {
+			return err
+		}
+
+		// The RowsAffected() method returns the number if rows affected from the query
+		// If no rows were affected that means that no record was deleted
+		// Which means the no record with the given id exists
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		} else if rowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		// Record a tombstone so that clients know to drop the movement from their copy
+		return recordMovementChange(ctx, tx, id, ChangeDelete)
+	})
+}
+
+// Method for getting the movements with any of the given names, compared case insensitively
+// This is how the prerequisites of many movements are looked up with a single query
+func (m MovementModel) GetMovementsByName(ctx context.Context, names []string) ([]*Movement, error) {
+	ctx, cancel := queryContext(ctx, m.Timeout)
+	defer cancel()
+
+	lowered := make([]string, len(names))
+	for i, name := range names {
+		lowered[i] = strings.ToLower(name)
 	}
 
-	// The RowsAffected() method returns the number if rows affected from the query
-	// If no rows were affected that means that no record was deleted
-	// Which means the no record with the given id exists
-	rowsAffected, err := result.RowsAffected()
+	query := `
+		SELECT ` + movementColumns + `
+		FROM movements
+		WHERE LOWER(name) = ANY($1)
+		ORDER BY id`
+
+	return m.queryMovements(ctx, query, pq.Array(lowered))
+}
+
+// Method for getting the variations of any of the given movements with a single query
+func (m MovementModel) GetVariations(ctx context.Context, ids []int64) ([]*Movement, error) {
+	ctx, cancel := queryContext(ctx, m.Timeout)
+	defer cancel()
+
+	query := `
+		SELECT ` + movementColumns + `
+		FROM movements
+		WHERE variation_of = ANY($1)
+		ORDER BY id`
+
+	return m.queryMovements(ctx, query, pq.Array(ids))
+}
+
+// Method for getting the movements with any of the given ids with a single query
+// The ids without a movement are left out
+func (m MovementModel) GetMovementsByID(ctx context.Context, ids []int64) ([]*Movement, error) {
+	ctx, cancel := queryContext(ctx, m.Timeout)
+	defer cancel()
+
+	query := `
+		SELECT ` + movementColumns + `
+		FROM movements
+		WHERE id = ANY($1)
+		ORDER BY id`
+
+	return m.queryMovements(ctx, query, pq.Array(ids))
+}
+
+// Method that runs a query selecting the movementColumns of movements and scans all of its rows
+func (m MovementModel) queryMovements(ctx context.Context, query string, args ...interface{}) ([]*Movement, error) {
+	rows, err := m.DB.QueryContext(ctx, query, args...)
 	if err != nil {
-		return err
-	} else if rowsAffected == 0 {
-		return ErrNotFound
+		return nil, err
 	}
+	defer rows.Close()
 
-	return nil
+	movements := []*Movement{}
+	for rows.Next() {
+		var movement Movement
+		err := rows.Scan(movement.scanTargets(nil)...)
+		if err != nil {
+			return nil, err
+		}
+		movements = append(movements, &movement)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return movements, nil
 }
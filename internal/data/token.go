@@ -1,9 +1,9 @@
 package data
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/sha256"
-	"database/sql"
 	"encoding/base32"
 	"time"
 
@@ -61,11 +61,15 @@ func ValidateTokenPlainText(v *validator.Validator, tokenPlaintext string) {
 
 // The token model type that warps a database connection
 type TokenModel struct {
-	DB *sql.DB
+	DB      Querier
+	Timeout time.Duration
 }
 
 // Function to add a token to the database
-func (m TokenModel) InsertOneToken(token *Token) error {
+func (m TokenModel) InsertOneToken(ctx context.Context, token *Token) error {
+	ctx, cancel := queryContext(ctx, m.Timeout)
+	defer cancel()
+
 	// SQL query for the insertion
 	query := `
 		INSERT INTO tokens (hash, expiry, user_id, scope)
@@ -73,12 +77,12 @@ func (m TokenModel) InsertOneToken(token *Token) error {
 
 	// Create the values slicr and execute the query
 	args := []interface{}{token.Hash, token.Expiry, token.User_id, token.Scope}
-	_, err := m.DB.Exec(query, args...)
+	_, err := m.DB.ExecContext(ctx, query, args...)
 	return err
 }
 
 // Shortcut function that creates a new token and inserts it into the database
-func (m TokenModel) NewToken(userID int64, ttl time.Duration, scope string) (*Token, error) {
+func (m TokenModel) NewToken(ctx context.Context, userID int64, ttl time.Duration, scope string) (*Token, error) {
 	// Generate the token
 	token, err := generateToken(userID, ttl, scope)
 	if err != nil {
@@ -86,18 +90,55 @@ func (m TokenModel) NewToken(userID int64, ttl time.Duration, scope string) (*To
 	}
 
 	// Insert the token into the database
-	err = m.InsertOneToken(token)
+	err = m.InsertOneToken(ctx, token)
 	return token, err
 }
 
 // Function to delete all tokens for a specific user and scope
-func (m TokenModel) DeleteTokens(userID int64, scope string) error {
+func (m TokenModel) DeleteTokens(ctx context.Context, userID int64, scope string) error {
+	ctx, cancel := queryContext(ctx, m.Timeout)
+	defer cancel()
+
 	// SQL query for the deletion
 	query := `
 		DELETE FROM tokens
 		WHERE user_id = $1 AND scope = $2`
 
 	// Execute the query
-	_, err := m.DB.Exec(query, userID, scope)
+	_, err := m.DB.ExecContext(ctx, query, userID, scope)
+	return err
+}
+
+// Function to delete all tokens of every scope for a specific user
+func (m TokenModel) DeleteAllTokensForUser(ctx context.Context, userID int64) error {
+	ctx, cancel := queryContext(ctx, m.Timeout)
+	defer cancel()
+
+	// SQL query for the deletion
+	query := `
+		DELETE FROM tokens
+		WHERE user_id = $1`
+
+	// Execute the query
+	_, err := m.DB.ExecContext(ctx, query, userID)
 	return err
 }
+
+// Function to delete all the expired tokens and return how many were deleted
+func (m TokenModel) DeleteExpiredTokens(ctx context.Context) (int64, error) {
+	ctx, cancel := queryContext(ctx, m.Timeout)
+	defer cancel()
+
+	// SQL query for the deletion
+	query := `
+		DELETE FROM tokens
+		WHERE expiry < $1`
+
+	// Execute the query
+	result, err := m.DB.ExecContext(ctx, query, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
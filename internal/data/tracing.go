@@ -0,0 +1,321 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The name of the tracer that the model spans are recorded with
+const tracerName = "github.com/arnab4477/Parkour_API/internal/data"
+
+// This returns the models with every call recorded as a span of tp
+// The models given to the fn of Transaction are traced too
+func WithTracing(m Models, tp trace.TracerProvider) Models {
+	tracer := tp.Tracer(tracerName)
+
+	traced := Models{
+		Movements:   tracedMovementStore{next: m.Movements, tracer: tracer},
+		Users:       tracedUserStore{next: m.Users, tracer: tracer},
+		Tokens:      tracedTokenStore{next: m.Tokens, tracer: tracer},
+		Permissions: tracedPermissionStore{next: m.Permissions, tracer: tracer},
+		Idempotency: tracedIdempotencyStore{next: m.Idempotency, tracer: tracer},
+	}
+
+	runInTx := m.runInTx
+	traced.runInTx = func(ctx context.Context, fn func(tx Models) error) (err error) {
+		ctx, span := tracer.Start(ctx, "Models.Transaction")
+		defer func() { endSpan(span, err) }()
+
+		return runInTx(ctx, func(tx Models) error {
+			return fn(WithTracing(tx, tp))
+		})
+	}
+
+	return traced
+}
+
+// Function that ends a span, marking it as failed if err is an unexpected error
+// Errors such as ErrNotFound are part of the normal flow of the handlers and are only recorded
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		if !errors.Is(err, ErrNotFound) && !errors.Is(err, ErrEditConflict) && !errors.Is(err, ErrDuplicateEmail) {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+}
+
+// tracedMovementStore records a span for every call to the MovementStore it wraps
+type tracedMovementStore struct {
+	next   MovementStore
+	tracer trace.Tracer
+}
+
+func (s tracedMovementStore) GetAllMovements(ctx context.Context, name string, difficulty string, skilltype []string, muscles []string, equipments []string, filters Filters) (movements []*Movement, err error) {
+	ctx, span := s.tracer.Start(ctx, "MovementStore.GetAllMovements", trace.WithAttributes(
+		attribute.Int("page", filters.Page),
+		attribute.Int("page_size", filters.PageSize),
+		attribute.String("sort", filters.Sort),
+		attribute.StringSlice("fields", filters.Fields),
+	))
+	defer func() { endSpan(span, err) }()
+
+	movements, err = s.next.GetAllMovements(ctx, name, difficulty, skilltype, muscles, equipments, filters)
+	span.SetAttributes(attribute.Int("movements", len(movements)))
+	return movements, err
+}
+
+func (s tracedMovementStore) InsertOneMovement(ctx context.Context, movement *Movement) (err error) {
+	ctx, span := s.tracer.Start(ctx, "MovementStore.InsertOneMovement")
+	defer func() { endSpan(span, err) }()
+
+	err = s.next.InsertOneMovement(ctx, movement)
+	span.SetAttributes(attribute.Int64("movement_id", movement.ID))
+	return err
+}
+
+func (s tracedMovementStore) GetOneMovement(ctx context.Context, id int64) (movement *Movement, err error) {
+	ctx, span := s.tracer.Start(ctx, "MovementStore.GetOneMovement", trace.WithAttributes(attribute.Int64("movement_id", id)))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.GetOneMovement(ctx, id)
+}
+
+func (s tracedMovementStore) UpdateOneMovement(ctx context.Context, movement *Movement) (err error) {
+	ctx, span := s.tracer.Start(ctx, "MovementStore.UpdateOneMovement", trace.WithAttributes(attribute.Int64("movement_id", movement.ID)))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.UpdateOneMovement(ctx, movement)
+}
+
+func (s tracedMovementStore) DeleteOneMovement(ctx context.Context, id int64) (err error) {
+	ctx, span := s.tracer.Start(ctx, "MovementStore.DeleteOneMovement", trace.WithAttributes(attribute.Int64("movement_id", id)))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.DeleteOneMovement(ctx, id)
+}
+
+func (s tracedMovementStore) GetMovementChanges(ctx context.Context, since int64) (changes *MovementChanges, err error) {
+	ctx, span := s.tracer.Start(ctx, "MovementStore.GetMovementChanges", trace.WithAttributes(attribute.Int64("since", since)))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.GetMovementChanges(ctx, since)
+}
+
+func (s tracedMovementStore) ExportMovements(ctx context.Context) (movements []*Movement, err error) {
+	ctx, span := s.tracer.Start(ctx, "MovementStore.ExportMovements")
+	defer func() { endSpan(span, err) }()
+
+	movements, err = s.next.ExportMovements(ctx)
+	span.SetAttributes(attribute.Int("movements", len(movements)))
+	return movements, err
+}
+
+func (s tracedMovementStore) ImportMovements(ctx context.Context, rows []ImportRow, dryRun bool) (report *ImportReport, err error) {
+	ctx, span := s.tracer.Start(ctx, "MovementStore.ImportMovements", trace.WithAttributes(
+		attribute.Int("rows", len(rows)),
+		attribute.Bool("dry_run", dryRun),
+	))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.ImportMovements(ctx, rows, dryRun)
+}
+
+func (s tracedMovementStore) GetCatalogStats(ctx context.Context) (stats *CatalogStats, err error) {
+	ctx, span := s.tracer.Start(ctx, "MovementStore.GetCatalogStats")
+	defer func() { endSpan(span, err) }()
+
+	return s.next.GetCatalogStats(ctx)
+}
+
+func (s tracedMovementStore) GetMovementsByName(ctx context.Context, names []string) (movements []*Movement, err error) {
+	ctx, span := s.tracer.Start(ctx, "MovementStore.GetMovementsByName", trace.WithAttributes(attribute.Int("names", len(names))))
+	defer func() { endSpan(span, err) }()
+
+	movements, err = s.next.GetMovementsByName(ctx, names)
+	span.SetAttributes(attribute.Int("movements", len(movements)))
+	return movements, err
+}
+
+func (s tracedMovementStore) GetVariations(ctx context.Context, ids []int64) (movements []*Movement, err error) {
+	ctx, span := s.tracer.Start(ctx, "MovementStore.GetVariations", trace.WithAttributes(attribute.Int("ids", len(ids))))
+	defer func() { endSpan(span, err) }()
+
+	movements, err = s.next.GetVariations(ctx, ids)
+	span.SetAttributes(attribute.Int("movements", len(movements)))
+	return movements, err
+}
+
+func (s tracedMovementStore) GetMovementsByID(ctx context.Context, ids []int64) (movements []*Movement, err error) {
+	ctx, span := s.tracer.Start(ctx, "MovementStore.GetMovementsByID", trace.WithAttributes(attribute.Int("ids", len(ids))))
+	defer func() { endSpan(span, err) }()
+
+	movements, err = s.next.GetMovementsByID(ctx, ids)
+	span.SetAttributes(attribute.Int("movements", len(movements)))
+	return movements, err
+}
+
+// tracedUserStore records a span for every call to the UserStore it wraps
+// The emails and tokens are left out of the spans on purpose
+type tracedUserStore struct {
+	next   UserStore
+	tracer trace.Tracer
+}
+
+func (s tracedUserStore) InsertOneUser(ctx context.Context, user *User) (err error) {
+	ctx, span := s.tracer.Start(ctx, "UserStore.InsertOneUser")
+	defer func() { endSpan(span, err) }()
+
+	return s.next.InsertOneUser(ctx, user)
+}
+
+func (s tracedUserStore) GetOneUserByEmail(ctx context.Context, email string) (user *User, err error) {
+	ctx, span := s.tracer.Start(ctx, "UserStore.GetOneUserByEmail")
+	defer func() { endSpan(span, err) }()
+
+	return s.next.GetOneUserByEmail(ctx, email)
+}
+
+func (s tracedUserStore) UpdateOneUser(ctx context.Context, user *User) (err error) {
+	ctx, span := s.tracer.Start(ctx, "UserStore.UpdateOneUser", trace.WithAttributes(attribute.Int64("user_id", user.ID)))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.UpdateOneUser(ctx, user)
+}
+
+func (s tracedUserStore) GetUserFromToken(ctx context.Context, tokenScope, tokenPlaintext string) (user *User, err error) {
+	ctx, span := s.tracer.Start(ctx, "UserStore.GetUserFromToken", trace.WithAttributes(attribute.String("token_scope", tokenScope)))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.GetUserFromToken(ctx, tokenScope, tokenPlaintext)
+}
+
+func (s tracedUserStore) GetUsersByID(ctx context.Context, ids []int64) (users []*User, err error) {
+	ctx, span := s.tracer.Start(ctx, "UserStore.GetUsersByID", trace.WithAttributes(attribute.Int("ids", len(ids))))
+	defer func() { endSpan(span, err) }()
+
+	users, err = s.next.GetUsersByID(ctx, ids)
+	span.SetAttributes(attribute.Int("users", len(users)))
+	return users, err
+}
+
+// tracedTokenStore records a span for every call to the TokenStore it wraps
+type tracedTokenStore struct {
+	next   TokenStore
+	tracer trace.Tracer
+}
+
+func (s tracedTokenStore) InsertOneToken(ctx context.Context, token *Token) (err error) {
+	ctx, span := s.tracer.Start(ctx, "TokenStore.InsertOneToken", trace.WithAttributes(
+		attribute.Int64("user_id", token.User_id),
+		attribute.String("token_scope", token.Scope),
+	))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.InsertOneToken(ctx, token)
+}
+
+func (s tracedTokenStore) NewToken(ctx context.Context, userID int64, ttl time.Duration, scope string) (token *Token, err error) {
+	ctx, span := s.tracer.Start(ctx, "TokenStore.NewToken", trace.WithAttributes(
+		attribute.Int64("user_id", userID),
+		attribute.String("token_scope", scope),
+	))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.NewToken(ctx, userID, ttl, scope)
+}
+
+func (s tracedTokenStore) DeleteTokens(ctx context.Context, userID int64, scope string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "TokenStore.DeleteTokens", trace.WithAttributes(
+		attribute.Int64("user_id", userID),
+		attribute.String("token_scope", scope),
+	))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.DeleteTokens(ctx, userID, scope)
+}
+
+func (s tracedTokenStore) DeleteAllTokensForUser(ctx context.Context, userID int64) (err error) {
+	ctx, span := s.tracer.Start(ctx, "TokenStore.DeleteAllTokensForUser", trace.WithAttributes(attribute.Int64("user_id", userID)))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.DeleteAllTokensForUser(ctx, userID)
+}
+
+func (s tracedTokenStore) DeleteExpiredTokens(ctx context.Context) (deleted int64, err error) {
+	ctx, span := s.tracer.Start(ctx, "TokenStore.DeleteExpiredTokens")
+	defer func() { endSpan(span, err) }()
+
+	return s.next.DeleteExpiredTokens(ctx)
+}
+
+// tracedPermissionStore records a span for every call to the PermissionStore it wraps
+type tracedPermissionStore struct {
+	next   PermissionStore
+	tracer trace.Tracer
+}
+
+func (s tracedPermissionStore) GetAllForUser(ctx context.Context, userID int64) (permissions Permissions, err error) {
+	ctx, span := s.tracer.Start(ctx, "PermissionStore.GetAllForUser", trace.WithAttributes(attribute.Int64("user_id", userID)))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.GetAllForUser(ctx, userID)
+}
+
+func (s tracedPermissionStore) AddForUser(ctx context.Context, userID int64, codes ...string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "PermissionStore.AddForUser", trace.WithAttributes(
+		attribute.Int64("user_id", userID),
+		attribute.StringSlice("permissions", codes),
+	))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.AddForUser(ctx, userID, codes...)
+}
+
+// tracedIdempotencyStore records a span for every call to the IdempotencyStore it wraps
+// The keys and the stored responses are left out of the spans, the responses can hold tokens
+type tracedIdempotencyStore struct {
+	next   IdempotencyStore
+	tracer trace.Tracer
+}
+
+func (s tracedIdempotencyStore) ReserveIdempotencyKey(ctx context.Context, record *IdempotencyRecord) (existing *IdempotencyRecord, err error) {
+	ctx, span := s.tracer.Start(ctx, "IdempotencyStore.ReserveIdempotencyKey", trace.WithAttributes(attribute.String("route", record.Route)))
+	defer func() { endSpan(span, err) }()
+
+	existing, err = s.next.ReserveIdempotencyKey(ctx, record)
+	span.SetAttributes(attribute.Bool("reserved", err == nil && existing == nil))
+	return existing, err
+}
+
+func (s tracedIdempotencyStore) CompleteIdempotencyKey(ctx context.Context, record *IdempotencyRecord) (err error) {
+	ctx, span := s.tracer.Start(ctx, "IdempotencyStore.CompleteIdempotencyKey", trace.WithAttributes(
+		attribute.String("route", record.Route),
+		attribute.Int("status", record.Status),
+	))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.CompleteIdempotencyKey(ctx, record)
+}
+
+func (s tracedIdempotencyStore) ReleaseIdempotencyKey(ctx context.Context, key IdempotencyKey) (err error) {
+	ctx, span := s.tracer.Start(ctx, "IdempotencyStore.ReleaseIdempotencyKey", trace.WithAttributes(attribute.String("route", key.Route)))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.ReleaseIdempotencyKey(ctx, key)
+}
+
+func (s tracedIdempotencyStore) DeleteExpiredIdempotencyKeys(ctx context.Context) (deleted int64, err error) {
+	ctx, span := s.tracer.Start(ctx, "IdempotencyStore.DeleteExpiredIdempotencyKeys")
+	defer func() { endSpan(span, err) }()
+
+	deleted, err = s.next.DeleteExpiredIdempotencyKeys(ctx)
+	span.SetAttributes(attribute.Int64("deleted", deleted))
+	return deleted, err
+}
@@ -0,0 +1,122 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// How many times a transaction is attempted before a serialization failure is returned
+const maxTxAttempts = 3
+
+// Querier is the part of *sql.DB and *sql.Tx that the models use
+// This lets the same model run its queries on the pool or inside a transaction
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Method that runs fn with models whose calls all happen in a single transaction
+// The transaction is committed if fn returns nil and rolled back if it returns an error or panics
+// fn can be run more than once when the transaction hits a serialization failure,
+// so it must not have side effects outside of the models, such as writing a response
+// Calling Transaction on the models given to fn joins the transaction that is already running
+func (m Models) Transaction(ctx context.Context, fn func(tx Models) error) error {
+	return m.runInTx(ctx, fn)
+}
+
+// Function that runs fn in a transaction on db, unless db is a transaction already
+// In that case fn joins it and the outer transaction decides if the changes are kept
+func withTx(ctx context.Context, db Querier, fn func(tx Querier) error) error {
+	pool, ok := db.(*sql.DB)
+	if !ok {
+		return fn(db)
+	}
+
+	tx, err := pool.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = fn(tx)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Function that returns the models with all their queries running on db
+func newSQLModels(db Querier, queryTimeout time.Duration) Models {
+	return Models{
+		Movements:   MovementModel{DB: db, Timeout: queryTimeout},
+		Users:       UserModel{DB: db, Timeout: queryTimeout},
+		Tokens:      TokenModel{DB: db, Timeout: queryTimeout},
+		Permissions: PermissionModel{DB: db, Timeout: queryTimeout},
+		Idempotency: IdempotencyModel{DB: db, Timeout: queryTimeout},
+	}
+}
+
+// Function that runs fn in a serializable transaction, retrying it on serialization failures
+func runInSQLTx(ctx context.Context, db *sql.DB, queryTimeout time.Duration, fn func(tx Models) error) error {
+	for attempt := 1; ; attempt++ {
+		err := runInSQLTxOnce(ctx, db, queryTimeout, fn)
+		if err == nil || attempt == maxTxAttempts || !isSerializationFailure(err) {
+			return err
+		}
+
+		// Wait a little, with some jitter, so the conflicting transactions don't collide again
+		backoff := time.Duration(attempt*10+rand.Intn(10)) * time.Millisecond
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Function that runs fn once in a serializable transaction
+func runInSQLTxOnce(ctx context.Context, db *sql.DB, queryTimeout time.Duration, fn func(tx Models) error) (err error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return err
+	}
+
+	// Roll back if fn panics and let the panic carry on to the caller
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	// The models given to fn join this transaction when Transaction is called on them
+	txModels := newSQLModels(tx, queryTimeout)
+	txModels.runInTx = func(ctx context.Context, fn func(tx Models) error) error {
+		return fn(txModels)
+	}
+
+	err = fn(txModels)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Function that reports if err is a serialization failure or a deadlock, after which
+// PostgreSQL expects the whole transaction to be retried
+func isSerializationFailure(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "40001" || pqErr.Code == "40P01"
+	}
+	return false
+}
@@ -1,12 +1,14 @@
 package data
 
 import (
+	"context"
 	"crypto/sha256"
 	"database/sql"
 	"errors"
 	"time"
 
 	"github.com/arnab4477/Parkour_API/internal/validator"
+	"github.com/lib/pq"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -105,11 +107,15 @@ func ValidateUser(v *validator.Validator, user *User) {
 
 // UserModel struct which warps a SQL connectopn pool
 type UserModel struct {
-	DB *sql.DB
+	DB      Querier
+	Timeout time.Duration
 }
 
 // Function to insert a new user record to the database
-func (m UserModel) InsertOneUser(user *User) error {
+func (m UserModel) InsertOneUser(ctx context.Context, user *User) error {
+	ctx, cancel := queryContext(ctx, m.Timeout)
+	defer cancel()
+
 	// SQL query to insert an yser
 	query := `
 		INSERT INTO users (username, email, password_hash, activated)
@@ -126,7 +132,7 @@ func (m UserModel) InsertOneUser(user *User) error {
 
 	// Execute the query. If an user provides a duplicate email then it will return
 	// an error because of the UNIQYE constraint
-	err := m.DB.QueryRow(query, args...).Scan(&user.ID, &user.Version)
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&user.ID, &user.Version)
 	if err != nil {
 		switch {
 		case err.Error() == `pq: duplicate key value violates unique constraint "users_email_key"`:
@@ -139,10 +145,13 @@ func (m UserModel) InsertOneUser(user *User) error {
 }
 
 // Function to get one user by an  unique email
-func (m UserModel) GetOneUserByEmail(email string) (*User, error) {
+func (m UserModel) GetOneUserByEmail(ctx context.Context, email string) (*User, error) {
+	ctx, cancel := queryContext(ctx, m.Timeout)
+	defer cancel()
+
 	// SQL query to retrieve one user from the database with email
 	query := `
-		SELECT id, username, password_hash, email, version
+		SELECT id, username, password_hash, email, activated, version
 		FROM Users
 		WHERE email=$1`
 
@@ -150,11 +159,12 @@ func (m UserModel) GetOneUserByEmail(email string) (*User, error) {
 	var user User
 
 	// Execute the query
-	err := m.DB.QueryRow(query, email).Scan(
+	err := m.DB.QueryRowContext(ctx, query, email).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Password.hash,
 		&user.Email,
+		&user.Activated,
 		&user.Version,
 	)
 
@@ -170,8 +180,45 @@ func (m UserModel) GetOneUserByEmail(email string) (*User, error) {
 	return &user, nil
 }
 
+// Function to get the users with any of the given ids with a single query
+// The password hashes are not selected as these users are only shown to others
+func (m UserModel) GetUsersByID(ctx context.Context, ids []int64) ([]*User, error) {
+	ctx, cancel := queryContext(ctx, m.Timeout)
+	defer cancel()
+
+	query := `
+		SELECT id, username, email, activated, version
+		FROM Users
+		WHERE id = ANY($1)
+		ORDER BY id`
+
+	rows, err := m.DB.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*User{}
+	for rows.Next() {
+		var user User
+		err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.Activated, &user.Version)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, &user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
 // Function to update one user
-func (m UserModel) UpdateOneUser(user *User) error {
+func (m UserModel) UpdateOneUser(ctx context.Context, user *User) error {
+	ctx, cancel := queryContext(ctx, m.Timeout)
+	defer cancel()
+
 	// SQL query to update one user record in the database
 	query := `
 		UPDATE users
@@ -189,13 +236,16 @@ func (m UserModel) UpdateOneUser(user *User) error {
 	}
 
 	// Execute the SQLquery
-	err := m.DB.QueryRow(query, args...).Scan(&user.Version)
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&user.Version)
 
 	if err != nil {
 		switch {
 		// If the user triedd to update email to a duplicate one
 		case err.Error() == `pq: duplicate key value violates unique constraint "users_email_key"`:
 			return ErrDuplicateEmail
+		// If no rows were affected then the version has changed since the user was read
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
 		default:
 			return err
 		}
@@ -204,7 +254,9 @@ func (m UserModel) UpdateOneUser(user *User) error {
 }
 
 // Function to get one user by token
-func (m UserModel) GetUserFromToken(tokenScope, tokenPlaintext string) (*User, error) {
+func (m UserModel) GetUserFromToken(ctx context.Context, tokenScope, tokenPlaintext string) (*User, error) {
+	ctx, cancel := queryContext(ctx, m.Timeout)
+	defer cancel()
 
 	// Hash of the plaintext token
 	tokenHash := sha256.Sum256([]byte(tokenPlaintext))
@@ -223,7 +275,7 @@ func (m UserModel) GetUserFromToken(tokenScope, tokenPlaintext string) (*User, e
 
 	// Create an instance of the user struct and execute the query
 	var user User
-	err := m.DB.QueryRow(query, args...).Scan(
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
@@ -0,0 +1,166 @@
+package jsonlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level type for the severity of a log entry
+type Level int8
+
+// The levels from the least to the most severe
+// Nothing is logged at LevelOff, it is only used as a minimum level
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelError
+	LevelFatal
+	LevelOff
+)
+
+// The formats a logger can write its entries in
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Method that returns the level as it is written in the log entries
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelError:
+		return "ERROR"
+	case LevelFatal:
+		return "FATAL"
+	case LevelOff:
+		return "OFF"
+	default:
+		return ""
+	}
+}
+
+// Function that returns the level for a name such as "info", as given in a flag
+func ParseLevel(name string) (Level, error) {
+	for l := LevelDebug; l <= LevelOff; l++ {
+		if strings.EqualFold(name, l.String()) {
+			return l, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level %q", name)
+}
+
+// Logger struct writes the entries at or above its minimum level to out
+type Logger struct {
+	out      io.Writer
+	minLevel Level
+	format   string
+	mu       sync.Mutex
+}
+
+// This returns a new logger, the format is either FormatJSON or FormatText
+func New(out io.Writer, minLevel Level, format string) *Logger {
+	return &Logger{
+		out:      out,
+		minLevel: minLevel,
+		format:   format,
+	}
+}
+
+// Method that logs a message at the DEBUG level
+func (l *Logger) PrintDebug(message string, properties map[string]string) {
+	l.print(LevelDebug, message, properties)
+}
+
+// Method that logs a message at the INFO level
+func (l *Logger) PrintInfo(message string, properties map[string]string) {
+	l.print(LevelInfo, message, properties)
+}
+
+// Method that logs an error at the ERROR level
+func (l *Logger) PrintError(err error, properties map[string]string) {
+	l.print(LevelError, err.Error(), properties)
+}
+
+// Method that logs an error at the FATAL level and exits the program
+func (l *Logger) PrintFatal(err error, properties map[string]string) {
+	l.print(LevelFatal, err.Error(), properties)
+	os.Exit(1)
+}
+
+// Method that writes a log entry in the format of the logger
+func (l *Logger) print(level Level, message string, properties map[string]string) (int, error) {
+	if level < l.minLevel {
+		return 0, nil
+	}
+
+	entry := struct {
+		Level      string            `json:"level"`
+		Time       string            `json:"time"`
+		Message    string            `json:"message"`
+		Properties map[string]string `json:"properties,omitempty"`
+	}{
+		Level:      level.String(),
+		Time:       time.Now().UTC().Format(time.RFC3339),
+		Message:    message,
+		Properties: properties,
+	}
+
+	var line []byte
+	if l.format == FormatText {
+		line = textLine(entry.Time, entry.Level, entry.Message, properties)
+	} else {
+		var err error
+		line, err = json.Marshal(entry)
+		if err != nil {
+			line = []byte(LevelError.String() + ": unable to marshal log message: " + err.Error())
+		}
+	}
+
+	// Lock so that two entries are never written into each other
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.out.Write(append(line, '\n'))
+}
+
+// Function that returns an entry as a line of key=value pairs, with the properties sorted by key
+func textLine(time, level, message string, properties map[string]string) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "time=%s level=%s message=%s", time, level, quote(message))
+
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fmt.Fprintf(&b, " %s=%s", key, quote(properties[key]))
+	}
+	return []byte(b.String())
+}
+
+// Function that quotes a value if it would be ambiguous without the quotes
+func quote(value string) string {
+	if value == "" || strings.ContainsAny(value, " \t\n\r\"=") {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+// Method that logs p at the ERROR level, so that the logger can be used by a log.Logger
+// such as the error log of http.Server
+func (l *Logger) Write(p []byte) (int, error) {
+	_, err := l.print(LevelError, strings.TrimSpace(string(p)), nil)
+	return len(p), err
+}
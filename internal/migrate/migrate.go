@@ -0,0 +1,381 @@
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Constants for the state of a migration
+const (
+	StateApplied  = "applied"
+	StatePending  = "pending"
+	StateModified = "modified"
+)
+
+// Key of the advisory lock held while migrating, so that replicas starting
+// together wait for each other instead of running the same migrations twice
+const lockKey = 7_001_000_001
+
+// Custom errors
+var (
+	ErrChecksumMismatch = errors.New("an applied migration has been modified")
+	ErrDirty            = errors.New("the database was left dirty by a failed migration")
+)
+
+// Regular expression for the migration file names, such as 000001_create_movements_table.up.sql
+var fileRegEx = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migration struct to hold a single pair of up and down migration files
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// Status struct to hold the state of a migration in the database
+type Status struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	State     string     `json:"state"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// Migrator struct which warps a SQL connection pool and the migrations to run against it
+type Migrator struct {
+	DB         *sql.DB
+	Migrations []Migration
+}
+
+// This returns a Migrator with the migrations read from the .sql files of fsys
+func New(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		matches := fileRegEx.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, exists := byVersion[version]
+		if !exists {
+			migration = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = migration
+		}
+
+		// The checksum only covers the up migration because that is what has been applied
+		if matches[3] == "up" {
+			sum := sha256.Sum256(content)
+			migration.Up = string(content)
+			migration.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	m := &Migrator{DB: db}
+	for _, migration := range byVersion {
+		if migration.Checksum == "" {
+			return nil, fmt.Errorf("migration %d has no up file", migration.Version)
+		}
+		m.Migrations = append(m.Migrations, *migration)
+	}
+
+	sort.Slice(m.Migrations, func(i, j int) bool {
+		return m.Migrations[i].Version < m.Migrations[j].Version
+	})
+
+	return m, nil
+}
+
+// Method that applies all the pending migrations in order, each in its own transaction
+// It returns the migrations that were applied
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		checksums, err := appliedChecksums(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.Migrations {
+			checksum, exists := checksums[migration.Version]
+			if exists {
+				if checksum != migration.Checksum {
+					return fmt.Errorf("%w: %d_%s", ErrChecksumMismatch, migration.Version, migration.Name)
+				}
+				continue
+			}
+
+			err := inTx(ctx, conn, func(tx *sql.Tx) error {
+				_, err := tx.ExecContext(ctx, migration.Up)
+				if err != nil {
+					return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+				}
+
+				_, err = tx.ExecContext(ctx, `
+					INSERT INTO schema_migrations (version, name, checksum)
+					VALUES ($1, $2, $3)`, migration.Version, migration.Name, migration.Checksum)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+
+			applied = append(applied, migration)
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Method that reverts the latest applied migrations, at most steps of them
+// It returns the migrations that were reverted
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		checksums, err := appliedChecksums(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.Migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := m.Migrations[i]
+			if _, exists := checksums[migration.Version]; !exists {
+				continue
+			}
+
+			err := inTx(ctx, conn, func(tx *sql.Tx) error {
+				_, err := tx.ExecContext(ctx, migration.Down)
+				if err != nil {
+					return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+				}
+
+				_, err = tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, migration.Version)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+
+			reverted = append(reverted, migration)
+		}
+
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Method that returns the state of every migration
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		rows, err := conn.QueryContext(ctx, `SELECT version, checksum, applied_at FROM schema_migrations`)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		type record struct {
+			checksum  string
+			appliedAt time.Time
+		}
+		records := make(map[int64]record)
+
+		for rows.Next() {
+			var version int64
+			var r record
+
+			err := rows.Scan(&version, &r.checksum, &r.appliedAt)
+			if err != nil {
+				return err
+			}
+
+			records[version] = r
+		}
+
+		if err = rows.Err(); err != nil {
+			return err
+		}
+
+		for _, migration := range m.Migrations {
+			status := Status{Version: migration.Version, Name: migration.Name, State: StatePending}
+
+			if r, exists := records[migration.Version]; exists {
+				appliedAt := r.appliedAt
+				status.AppliedAt = &appliedAt
+				status.State = StateApplied
+				if r.checksum != migration.Checksum {
+					status.State = StateModified
+				}
+			}
+
+			statuses = append(statuses, status)
+		}
+
+		return nil
+	})
+
+	return statuses, err
+}
+
+// Method that runs fn on a single connection while holding the migration advisory lock
+// Advisory locks belong to a session, so every statement has to go through the same connection
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockKey)
+	if err != nil {
+		return err
+	}
+
+	// Use a fresh context to release the lock so that a cancelled ctx can not leave it held
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, lockKey)
+
+	err = m.ensureTable(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+// Method that creates the schema_migrations table if it does not exist yet
+// A table left by the migrate CLI, which only stores the latest version and a dirty flag,
+// is converted by recording every migration up to that version as applied
+func (m *Migrator) ensureTable(ctx context.Context, conn *sql.Conn) error {
+	var legacy bool
+	err := conn.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = 'schema_migrations' AND column_name = 'dirty'
+		)`).Scan(&legacy)
+	if err != nil {
+		return err
+	}
+
+	return inTx(ctx, conn, func(tx *sql.Tx) error {
+		var current int64 = -1
+
+		if legacy {
+			var dirty bool
+			err := tx.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&current, &dirty)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+			if dirty {
+				return fmt.Errorf("%w at version %d", ErrDirty, current)
+			}
+
+			_, err = tx.ExecContext(ctx, `DROP TABLE schema_migrations`)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.ExecContext(ctx, `
+			CREATE TABLE IF NOT EXISTS schema_migrations (
+				version bigint PRIMARY KEY,
+				name text NOT NULL,
+				checksum text NOT NULL,
+				applied_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+			)`)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.Migrations {
+			if migration.Version > current {
+				break
+			}
+
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO schema_migrations (version, name, checksum)
+				VALUES ($1, $2, $3)`, migration.Version, migration.Name, migration.Checksum)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Function that returns the checksums of the applied migrations keyed by their version
+func appliedChecksums(ctx context.Context, conn *sql.Conn) (map[int64]string, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, checksum FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	checksums := make(map[int64]string)
+
+	for rows.Next() {
+		var version int64
+		var checksum string
+
+		err := rows.Scan(&version, &checksum)
+		if err != nil {
+			return nil, err
+		}
+
+		checksums[version] = checksum
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return checksums, nil
+}
+
+// Function that runs fn in a transaction on conn, committing only if it returns no error
+func inTx(ctx context.Context, conn *sql.Conn, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = fn(tx)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,231 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"math"
+	"sync"
+	"time"
+)
+
+// Postgres is a sliding window limiter that keeps its counters in PostgreSQL
+// so that all the replicas of the API share the same limits
+//
+// A limit allows Burst requests in a window of Burst/Rate, the same average rate as the token bucket.
+// The window slides by weighing the count of the previous window by how much of it still overlaps
+//
+// If the database fails or takes longer than Timeout, the request is passed to the Fallback limiter
+// and the database is left alone for Cooldown, so a slow database doesn't slow down every request
+type Postgres struct {
+	DB       *sql.DB
+	Fallback Limiter
+	Timeout  time.Duration
+	Cooldown time.Duration
+	Logger   *log.Logger
+
+	mu        sync.Mutex
+	downUntil time.Time
+	now       func() time.Time
+}
+
+// This returns a new PostgreSQL limiter that falls back to fallback
+func NewPostgres(db *sql.DB, fallback Limiter, timeout time.Duration, logger *log.Logger) *Postgres {
+	return &Postgres{
+		DB:       db,
+		Fallback: fallback,
+		Timeout:  timeout,
+		Cooldown: 10 * time.Second,
+		Logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// Method that counts a request for key in its current window, if the limit allows it
+func (p *Postgres) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	if p.isDown() {
+		return p.Fallback.Allow(ctx, key, limit)
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, p.Timeout)
+	defer cancel()
+
+	result, err := p.allow(dbCtx, key, limit)
+	if err != nil {
+		// A client that went away says nothing about the database
+		if ctx.Err() == nil {
+			p.markDown(err)
+		}
+		return p.Fallback.Allow(ctx, key, limit)
+	}
+
+	return result, nil
+}
+
+// Method that does the counting for Allow
+// The windows are based on the clock of the replica, which is fine as long as the clocks are in sync
+func (p *Postgres) allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	window := secondsToDuration(float64(limit.Burst) / limit.Rate)
+	if window < time.Millisecond {
+		window = time.Millisecond
+	}
+	windowMs := window.Milliseconds()
+
+	now := p.now()
+	nowMs := now.UnixMilli()
+	current := nowMs - nowMs%windowMs
+	previous := current - windowMs
+
+	// How far into the current window we are and so how much of the previous one still counts
+	elapsed := time.Duration(nowMs-current) * time.Millisecond
+	weight := 1 - float64(elapsed)/float64(window)
+
+	// Get the counts of the previous and the current window
+	query := `
+		SELECT window_start, count
+		FROM rate_limits
+		WHERE key = $1 AND window_start IN ($2, $3)`
+
+	rows, err := p.DB.QueryContext(ctx, query, key, previous, current)
+	if err != nil {
+		return Result{}, err
+	}
+	defer rows.Close()
+
+	var previousCount, currentCount int
+	for rows.Next() {
+		var start int64
+		var count int
+		err := rows.Scan(&start, &count)
+		if err != nil {
+			return Result{}, err
+		}
+
+		if start == current {
+			currentCount = count
+		} else {
+			previousCount = count
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return Result{}, err
+	}
+
+	// The requests that are left for the current window after the previous one is weighed in
+	available := float64(limit.Burst) - float64(previousCount)*weight
+
+	result := Result{Limit: limit.Burst}
+	if float64(currentCount)+1 > available {
+		result.RetryAfter = retryAfter(limit.Burst, previousCount, currentCount, elapsed, window)
+		result.Reset = resetAfter(currentCount, elapsed, window)
+		return result, nil
+	}
+
+	// Count the request, the WHERE clause makes sure that the replicas counting
+	// at the same time cannot go over the limit together
+	query = `
+		INSERT INTO rate_limits (key, window_start, count, expires_at)
+		VALUES ($1, $2, 1, $3)
+		ON CONFLICT (key, window_start) DO UPDATE
+		SET count = rate_limits.count + 1
+		WHERE rate_limits.count + 1 <= $4::float8
+		RETURNING count`
+
+	// A window is still needed while it is the previous one
+	expiresAt := time.UnixMilli(current).Add(2 * window)
+
+	err = p.DB.QueryRowContext(ctx, query, key, current, expiresAt, available).Scan(&currentCount)
+	if err != nil {
+		// No row means that another request took the last one first
+		if errors.Is(err, sql.ErrNoRows) {
+			result.RetryAfter = retryAfter(limit.Burst, previousCount, int(math.Ceil(available)), elapsed, window)
+			result.Reset = resetAfter(int(math.Ceil(available)), elapsed, window)
+			return result, nil
+		}
+		return Result{}, err
+	}
+
+	result.Allowed = true
+	result.Remaining = int(math.Max(0, math.Floor(available-float64(currentCount))))
+	result.Reset = resetAfter(currentCount, elapsed, window)
+	return result, nil
+}
+
+// Function that returns how long until a request would fit under the limit again
+func retryAfter(burst, previousCount, currentCount int, elapsed, window time.Duration) time.Duration {
+	room := float64(burst - 1)
+
+	// The current window is not full, only the weight of the previous one has to go down
+	if float64(currentCount) <= room && previousCount > 0 {
+		at := float64(window) * (1 - (room-float64(currentCount))/float64(previousCount))
+		return time.Duration(at) - elapsed
+	}
+
+	// Else the current window has to become the previous one and lose enough of its weight
+	at := float64(window)
+	if currentCount > 0 {
+		at += float64(window) * math.Max(0, 1-room/float64(currentCount))
+	}
+	return time.Duration(at) - elapsed
+}
+
+// Function that returns how long until none of the counted requests count anymore
+func resetAfter(currentCount int, elapsed, window time.Duration) time.Duration {
+	if currentCount == 0 {
+		return window - elapsed
+	}
+	return 2*window - elapsed
+}
+
+// Method that reports if the database is being left alone after a failure
+func (p *Postgres) isDown() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.now().Before(p.downUntil)
+}
+
+// Method that leaves the database alone for the cooldown after it has failed
+func (p *Postgres) markDown(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Only log once for each cooldown as there will be plenty of failing requests
+	if p.now().Before(p.downUntil) {
+		return
+	}
+	p.downUntil = p.now().Add(p.Cooldown)
+
+	if p.Logger != nil {
+		p.Logger.Printf("rate limiter: falling back to local limits for %s: %v", p.Cooldown, err)
+	}
+}
+
+// Method that deletes the counters of the windows that are over
+func (p *Postgres) Sweep(ctx context.Context) (int64, error) {
+	result, err := p.DB.ExecContext(ctx, `DELETE FROM rate_limits WHERE expires_at < $1`, p.now())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// Method that calls Sweep every interval until stop is closed
+func (p *Postgres) SweepEvery(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			_, err := p.Sweep(ctx)
+			cancel()
+			if err != nil && p.Logger != nil {
+				p.Logger.Printf("rate limiter: sweeping the counters: %v", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit struct holds the rate at which requests are allowed and how many can be made in a burst
+type Limit struct {
+	Rate  float64 // Requests per second
+	Burst int
+}
+
+// Result struct holds the outcome of a request to a limiter
+// It has everything needed for the RateLimit-* and Retry-After headers
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration // How long until the next request is allowed, if this one was not
+	Reset      time.Duration // How long until the limit is fully available again
+}
+
+// Limiter is the interface for the rate limiter backends
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// A token bucket for a single key
+type bucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// Memory is a token bucket limiter that keeps its buckets in memory
+// It is only accurate for a single instance of the API
+type Memory struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+// This returns a new in-memory limiter
+func NewMemory() *Memory {
+	return &Memory{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Method that takes a token from the bucket of key, if there is one
+func (m *Memory) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+
+	// A new bucket starts full
+	b, exists := m.buckets[key]
+	if !exists {
+		b = &bucket{tokens: float64(limit.Burst), lastSeen: now}
+		m.buckets[key] = b
+	}
+
+	// Refill the bucket for the time that has passed since it was last seen
+	elapsed := now.Sub(b.lastSeen).Seconds()
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+elapsed*limit.Rate)
+	b.lastSeen = now
+
+	result := Result{Limit: limit.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - b.tokens) / limit.Rate)
+	}
+
+	result.Remaining = int(math.Floor(b.tokens))
+	result.Reset = secondsToDuration((float64(limit.Burst) - b.tokens) / limit.Rate)
+
+	return result, nil
+}
+
+// Method that deletes the buckets that have not been used for longer than idle
+// A bucket that has been idle for long enough is full again, so deleting it changes nothing
+func (m *Memory) Sweep(idle time.Duration) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deleted := 0
+	now := m.now()
+	for key, b := range m.buckets {
+		if now.Sub(b.lastSeen) > idle {
+			delete(m.buckets, key)
+			deleted++
+		}
+	}
+	return deleted
+}
+
+// Method that calls Sweep every interval until stop is closed
+func (m *Memory) SweepEvery(interval, idle time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.Sweep(idle)
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Function that converts a number of seconds to a duration
+func secondsToDuration(seconds float64) time.Duration {
+	if math.IsInf(seconds, 0) || math.IsNaN(seconds) {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
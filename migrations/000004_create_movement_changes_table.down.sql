@@ -0,0 +1 @@
+DROP TABLE IF EXISTS movement_changes;
\ No newline at end of file
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS movement_changes (
+    id bigserial PRIMARY KEY,
+    movement_id bigint NOT NULL,
+    operation text NOT NULL,
+    txid bigint NOT NULL DEFAULT txid_current(),
+    changed_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS movement_changes_txid_idx ON movement_changes (txid);
+
+INSERT INTO movement_changes (movement_id, operation)
+SELECT id, 'insert' FROM movements;
@@ -0,0 +1,10 @@
+-- The counters are cheap to lose, so the table skips the write-ahead log
+CREATE UNLOGGED TABLE IF NOT EXISTS rate_limits (
+    key text NOT NULL,
+    window_start bigint NOT NULL,
+    count integer NOT NULL,
+    expires_at timestamp(0) with time zone NOT NULL,
+    PRIMARY KEY (key, window_start)
+);
+
+CREATE INDEX IF NOT EXISTS rate_limits_expires_at_idx ON rate_limits (expires_at);
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS movements_changed ON movements;
+DROP FUNCTION IF EXISTS notify_movements_changed();
@@ -0,0 +1,14 @@
+-- Tell the API replicas to drop their cached movements whenever the table changes,
+-- the notifications are only delivered once the transaction commits
+CREATE OR REPLACE FUNCTION notify_movements_changed() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('movements_changed', TG_OP);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS movements_changed ON movements;
+
+CREATE TRIGGER movements_changed
+AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON movements
+FOR EACH STATEMENT EXECUTE PROCEDURE notify_movements_changed();
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS movements_variation_of_idx;
+ALTER TABLE movements DROP COLUMN IF EXISTS variation_of;
+ALTER TABLE movements DROP COLUMN IF EXISTS creator_id;
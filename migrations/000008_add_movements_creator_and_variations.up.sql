@@ -0,0 +1,5 @@
+ALTER TABLE movements ADD COLUMN IF NOT EXISTS creator_id bigint REFERENCES users ON DELETE SET NULL;
+ALTER TABLE movements ADD COLUMN IF NOT EXISTS variation_of bigint REFERENCES movements ON DELETE SET NULL;
+
+-- The variations of a page of movements are looked up all at once
+CREATE INDEX IF NOT EXISTS movements_variation_of_idx ON movements (variation_of);
@@ -0,0 +1,16 @@
+-- Unlike the rate limits these are logged, losing them would let a retry create a duplicate
+-- The user_id is 0 for the requests without a bearer token, so it can't reference the users
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key text NOT NULL,
+    user_id bigint NOT NULL,
+    route text NOT NULL,
+    fingerprint text NOT NULL,
+    -- The response, NULL while the request is being processed
+    status integer,
+    header jsonb,
+    body bytea,
+    expires_at timestamp(0) with time zone NOT NULL,
+    PRIMARY KEY (key, user_id, route)
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
@@ -0,0 +1,5 @@
+-- The keys of different anonymous clients would clash without the column, they only hold replays so they can go
+DELETE FROM idempotency_keys WHERE client <> '';
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS client;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (key, user_id, route);
@@ -0,0 +1,4 @@
+-- The anonymous clients all have the user_id 0, so their keys are also told apart by their IP address
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS client text NOT NULL DEFAULT '';
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (key, user_id, client, route);
@@ -0,0 +1,9 @@
+// Package migrations embeds the SQL migration files so that they ship inside the binaries
+package migrations
+
+import "embed"
+
+// All the up and down migration files
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,294 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: parkour/v1/movements.proto
+
+package parkourv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	MovementService_ListMovements_FullMethodName      = "/parkour.v1.MovementService/ListMovements"
+	MovementService_GetMovement_FullMethodName        = "/parkour.v1.MovementService/GetMovement"
+	MovementService_CreateMovement_FullMethodName     = "/parkour.v1.MovementService/CreateMovement"
+	MovementService_UpdateMovement_FullMethodName     = "/parkour.v1.MovementService/UpdateMovement"
+	MovementService_DeleteMovement_FullMethodName     = "/parkour.v1.MovementService/DeleteMovement"
+	MovementService_GetMovementChanges_FullMethodName = "/parkour.v1.MovementService/GetMovementChanges"
+)
+
+// MovementServiceClient is the client API for MovementService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type MovementServiceClient interface {
+	ListMovements(ctx context.Context, in *ListMovementsRequest, opts ...grpc.CallOption) (*ListMovementsResponse, error)
+	GetMovement(ctx context.Context, in *GetMovementRequest, opts ...grpc.CallOption) (*GetMovementResponse, error)
+	CreateMovement(ctx context.Context, in *CreateMovementRequest, opts ...grpc.CallOption) (*CreateMovementResponse, error)
+	UpdateMovement(ctx context.Context, in *UpdateMovementRequest, opts ...grpc.CallOption) (*UpdateMovementResponse, error)
+	DeleteMovement(ctx context.Context, in *DeleteMovementRequest, opts ...grpc.CallOption) (*DeleteMovementResponse, error)
+	GetMovementChanges(ctx context.Context, in *GetMovementChangesRequest, opts ...grpc.CallOption) (*GetMovementChangesResponse, error)
+}
+
+type movementServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMovementServiceClient(cc grpc.ClientConnInterface) MovementServiceClient {
+	return &movementServiceClient{cc}
+}
+
+func (c *movementServiceClient) ListMovements(ctx context.Context, in *ListMovementsRequest, opts ...grpc.CallOption) (*ListMovementsResponse, error) {
+	out := new(ListMovementsResponse)
+	err := c.cc.Invoke(ctx, MovementService_ListMovements_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *movementServiceClient) GetMovement(ctx context.Context, in *GetMovementRequest, opts ...grpc.CallOption) (*GetMovementResponse, error) {
+	out := new(GetMovementResponse)
+	err := c.cc.Invoke(ctx, MovementService_GetMovement_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *movementServiceClient) CreateMovement(ctx context.Context, in *CreateMovementRequest, opts ...grpc.CallOption) (*CreateMovementResponse, error) {
+	out := new(CreateMovementResponse)
+	err := c.cc.Invoke(ctx, MovementService_CreateMovement_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *movementServiceClient) UpdateMovement(ctx context.Context, in *UpdateMovementRequest, opts ...grpc.CallOption) (*UpdateMovementResponse, error) {
+	out := new(UpdateMovementResponse)
+	err := c.cc.Invoke(ctx, MovementService_UpdateMovement_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *movementServiceClient) DeleteMovement(ctx context.Context, in *DeleteMovementRequest, opts ...grpc.CallOption) (*DeleteMovementResponse, error) {
+	out := new(DeleteMovementResponse)
+	err := c.cc.Invoke(ctx, MovementService_DeleteMovement_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *movementServiceClient) GetMovementChanges(ctx context.Context, in *GetMovementChangesRequest, opts ...grpc.CallOption) (*GetMovementChangesResponse, error) {
+	out := new(GetMovementChangesResponse)
+	err := c.cc.Invoke(ctx, MovementService_GetMovementChanges_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MovementServiceServer is the server API for MovementService service.
+// All implementations must embed UnimplementedMovementServiceServer
+// for forward compatibility
+type MovementServiceServer interface {
+	ListMovements(context.Context, *ListMovementsRequest) (*ListMovementsResponse, error)
+	GetMovement(context.Context, *GetMovementRequest) (*GetMovementResponse, error)
+	CreateMovement(context.Context, *CreateMovementRequest) (*CreateMovementResponse, error)
+	UpdateMovement(context.Context, *UpdateMovementRequest) (*UpdateMovementResponse, error)
+	DeleteMovement(context.Context, *DeleteMovementRequest) (*DeleteMovementResponse, error)
+	GetMovementChanges(context.Context, *GetMovementChangesRequest) (*GetMovementChangesResponse, error)
+	mustEmbedUnimplementedMovementServiceServer()
+}
+
+// UnimplementedMovementServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedMovementServiceServer struct {
+}
+
+func (UnimplementedMovementServiceServer) ListMovements(context.Context, *ListMovementsRequest) (*ListMovementsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMovements not implemented")
+}
+func (UnimplementedMovementServiceServer) GetMovement(context.Context, *GetMovementRequest) (*GetMovementResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMovement not implemented")
+}
+func (UnimplementedMovementServiceServer) CreateMovement(context.Context, *CreateMovementRequest) (*CreateMovementResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateMovement not implemented")
+}
+func (UnimplementedMovementServiceServer) UpdateMovement(context.Context, *UpdateMovementRequest) (*UpdateMovementResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateMovement not implemented")
+}
+func (UnimplementedMovementServiceServer) DeleteMovement(context.Context, *DeleteMovementRequest) (*DeleteMovementResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteMovement not implemented")
+}
+func (UnimplementedMovementServiceServer) GetMovementChanges(context.Context, *GetMovementChangesRequest) (*GetMovementChangesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMovementChanges not implemented")
+}
+func (UnimplementedMovementServiceServer) mustEmbedUnimplementedMovementServiceServer() {}
+
+// UnsafeMovementServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MovementServiceServer will
+// result in compilation errors.
+type UnsafeMovementServiceServer interface {
+	mustEmbedUnimplementedMovementServiceServer()
+}
+
+func RegisterMovementServiceServer(s grpc.ServiceRegistrar, srv MovementServiceServer) {
+	s.RegisterService(&MovementService_ServiceDesc, srv)
+}
+
+func _MovementService_ListMovements_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMovementsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MovementServiceServer).ListMovements(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MovementService_ListMovements_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MovementServiceServer).ListMovements(ctx, req.(*ListMovementsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MovementService_GetMovement_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMovementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MovementServiceServer).GetMovement(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MovementService_GetMovement_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MovementServiceServer).GetMovement(ctx, req.(*GetMovementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MovementService_CreateMovement_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateMovementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MovementServiceServer).CreateMovement(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MovementService_CreateMovement_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MovementServiceServer).CreateMovement(ctx, req.(*CreateMovementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MovementService_UpdateMovement_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateMovementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MovementServiceServer).UpdateMovement(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MovementService_UpdateMovement_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MovementServiceServer).UpdateMovement(ctx, req.(*UpdateMovementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MovementService_DeleteMovement_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteMovementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MovementServiceServer).DeleteMovement(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MovementService_DeleteMovement_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MovementServiceServer).DeleteMovement(ctx, req.(*DeleteMovementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MovementService_GetMovementChanges_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMovementChangesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MovementServiceServer).GetMovementChanges(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MovementService_GetMovementChanges_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MovementServiceServer).GetMovementChanges(ctx, req.(*GetMovementChangesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// MovementService_ServiceDesc is the grpc.ServiceDesc for MovementService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MovementService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "parkour.v1.MovementService",
+	HandlerType: (*MovementServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListMovements",
+			Handler:    _MovementService_ListMovements_Handler,
+		},
+		{
+			MethodName: "GetMovement",
+			Handler:    _MovementService_GetMovement_Handler,
+		},
+		{
+			MethodName: "CreateMovement",
+			Handler:    _MovementService_CreateMovement_Handler,
+		},
+		{
+			MethodName: "UpdateMovement",
+			Handler:    _MovementService_UpdateMovement_Handler,
+		},
+		{
+			MethodName: "DeleteMovement",
+			Handler:    _MovementService_DeleteMovement_Handler,
+		},
+		{
+			MethodName: "GetMovementChanges",
+			Handler:    _MovementService_GetMovementChanges_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "parkour/v1/movements.proto",
+}